@@ -0,0 +1,40 @@
+package main
+
+// findSavedSession ищет в конфиге сессию, соответствующую живой сессии:
+// сначала по имени, затем по каталогу
+func findSavedSession(s TmuxSession) *FavouriteSession {
+	if fs := Config.Find(s.Name); fs != nil {
+		return fs
+	}
+	for i := range Config.Sessions {
+		if normalizePath(Config.Sessions[i].Path) == normalizePath(s.Path) {
+			return &Config.Sessions[i]
+		}
+	}
+	return nil
+}
+
+// syncActivity обновляет last_used сохранённых сессий по времени активности живых сессий,
+// чтобы история отражала и сессии, на которые переключались не через pr
+func syncActivity(sessions []TmuxSession) {
+	updated := 0
+	for _, s := range sessions {
+		if s.LastActivity.IsZero() {
+			continue
+		}
+		fs := findSavedSession(s)
+		if fs == nil {
+			continue
+		}
+		if fs.LastUsed != nil && !fs.LastUsed.Before(s.LastActivity) {
+			continue
+		}
+		t := s.LastActivity
+		fs.LastUsed = &t
+		updated++
+	}
+	if updated > 0 {
+		Config.changed = true
+	}
+	info("updated %d sessions", updated)
+}
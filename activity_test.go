@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSyncActivity(t *testing.T) {
+	withHome(t, "/home/user")
+	older := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	newest := time.Date(2024, 5, 3, 10, 0, 0, 0, time.UTC)
+	withConfig(t, FavouritesConfig{Sessions: []FavouriteSession{
+		{Name: "byname", Path: "/src/byname", LastUsed: &older},
+		{Name: "bypath", Path: "~/work/bypath"},
+		{Name: "fresh", Path: "/src/fresh", LastUsed: &newest},
+		{Name: "idle", Path: "/src/idle", LastUsed: &older},
+	}})
+
+	syncActivity([]TmuxSession{
+		{Name: "byname", Path: "/elsewhere", LastActivity: newer},
+		{Name: "bypath2", Path: "/home/user/work/bypath", LastActivity: newer},
+		{Name: "fresh", Path: "/src/fresh", LastActivity: newer},
+		{Name: "idle", Path: "/src/idle"},
+		{Name: "unsaved", Path: "/src/unsaved", LastActivity: newest},
+	})
+
+	want := map[string]time.Time{
+		"byname": newer,  // найдена по имени
+		"bypath": newer,  // найдена по каталогу с ~
+		"fresh":  newest, // время не идёт назад
+		"idle":   older,  // у живой сессии нет времени активности
+	}
+	for name, w := range want {
+		fs := Config.Find(name)
+		if fs.LastUsed == nil || !fs.LastUsed.Equal(w) {
+			t.Errorf("%s: last_used = %v, want %v", name, fs.LastUsed, w)
+		}
+	}
+	if len(Config.Sessions) != 4 {
+		t.Errorf("config has %d sessions, want 4: unsaved sessions must not be added", len(Config.Sessions))
+	}
+	if !Config.changed {
+		t.Error("syncActivity did not mark config as changed")
+	}
+}
+
+func TestSyncActivityNothingToUpdate(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	withConfig(t, FavouritesConfig{Sessions: []FavouriteSession{
+		{Name: "api", Path: "/src/api", LastUsed: &now},
+	}})
+	syncActivity([]TmuxSession{{Name: "api", Path: "/src/api", LastActivity: now}})
+	if Config.changed {
+		t.Error("syncActivity marked config as changed, but nothing moved forward")
+	}
+}
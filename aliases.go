@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// minAliasedNameLength это длина имени, начиная с которой -alias-suggest предлагает алиас
+const minAliasedNameLength = 7
+
+// initials возвращает первые буквы слов имени ("my-cool_project" -> "mcp", "myProject" -> "mp")
+func initials(name string) string {
+	var b strings.Builder
+	prev := ' '
+	for _, ch := range name {
+		if unicode.IsLetter(ch) || unicode.IsDigit(ch) {
+			if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) || unicode.IsUpper(ch) && unicode.IsLower(prev) {
+				b.WriteRune(unicode.ToLower(ch))
+			}
+		}
+		prev = ch
+	}
+	return b.String()
+}
+
+// takenNames возвращает все имена и алиасы сессий из конфига
+func takenNames() map[string]bool {
+	taken := make(map[string]bool)
+	for _, fs := range Config.Sessions {
+		taken[fs.Name] = true
+		for _, a := range fs.Aliases {
+			taken[a] = true
+		}
+	}
+	return taken
+}
+
+// suggestAlias предлагает короткий алиас для name: инициалы или кратчайший уникальный префикс.
+// Возвращает пустую строку, если подходящего алиаса нет.
+func suggestAlias(name string, taken map[string]bool) string {
+	if a := initials(name); len(a) >= 2 && !taken[a] {
+		return a
+	}
+	runes := []rune(name)
+	for l := 2; l < len(runes); l++ {
+		prefix := string(runes[:l])
+		unique := !taken[prefix]
+		for other := range taken {
+			if other != name && strings.HasPrefix(other, prefix) {
+				unique = false
+				break
+			}
+		}
+		if unique {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// suggestAliases печатает предлагаемые алиасы для сессий из конфига с длинными именами и без алиасов;
+// если apply, добавляет их в конфиг
+func suggestAliases(apply bool) {
+	taken := takenNames()
+	count := 0
+	for _, fs := range Config.Sessions {
+		if len(fs.Aliases) > 0 || len([]rune(fs.Name)) < minAliasedNameLength {
+			continue
+		}
+		alias := suggestAlias(fs.Name, taken)
+		if alias == "" {
+			continue
+		}
+		taken[alias] = true
+		count++
+		fmt.Printf("%s -> %s\n", fs.Name, alias)
+		if apply {
+			Config.AddAlias(fs.Name, alias)
+		}
+	}
+	if count == 0 {
+		fmt.Printf("no aliases to suggest\n")
+	} else if !apply {
+		fmt.Printf("run with -apply to add these aliases to the config\n")
+	}
+}
+
+// aliasConflict это алиас сессии из конфига, который не работает или мешает другой сессии
+type aliasConflict struct {
+	Session int // номер сессии в конфиге
+	Alias   int // номер алиаса в списке алиасов сессии
+	Reason  string
+}
+
+// findAliasConflicts ищет алиасы, повторяющие имя какой-либо сессии из конфига
+// или алиас, встретившийся раньше. Первый из повторяющихся алиасов считается правильным.
+func findAliasConflicts(sessions []FavouriteSession) []aliasConflict {
+	names := make(map[string]bool)
+	for _, fs := range sessions {
+		names[fs.Name] = true
+	}
+	owners := make(map[string]string)
+	conflicts := []aliasConflict{}
+	for i, fs := range sessions {
+		for j, a := range fs.Aliases {
+			reason := ""
+			if a == fs.Name {
+				reason = "same as the session name"
+			} else if names[a] {
+				reason = "shadows session " + a
+			} else if owner, ok := owners[a]; ok && owner == fs.Name {
+				reason = "duplicate alias"
+			} else if ok {
+				reason = "already an alias of " + owner
+			}
+			if reason != "" {
+				conflicts = append(conflicts, aliasConflict{i, j, reason})
+				continue
+			}
+			owners[a] = fs.Name
+		}
+	}
+	return conflicts
+}
+
+// dedupeAliases печатает конфликтующие алиасы; с apply удаляет их из конфига
+func dedupeAliases(apply bool) {
+	conflicts := findAliasConflicts(Config.Sessions)
+	if len(conflicts) == 0 {
+		info("no alias conflicts")
+		return
+	}
+	for _, c := range conflicts {
+		fs := &Config.Sessions[c.Session]
+		fmt.Printf("%s: alias %s %s\n", fs.Name, fs.Aliases[c.Alias], c.Reason)
+	}
+	if !apply {
+		return
+	}
+	// удаляем с конца, чтобы номера ещё не удалённых алиасов оставались верными
+	for k := len(conflicts) - 1; k >= 0; k-- {
+		c := conflicts[k]
+		fs := &Config.Sessions[c.Session]
+		fs.Aliases = append(fs.Aliases[:c.Alias], fs.Aliases[c.Alias+1:]...)
+	}
+	Config.changed = true
+	info("removed %d aliases", len(conflicts))
+}
+
+// manageAlias добавляет (action "add") или удаляет (action "remove") алиас сессии из конфига.
+// Сессия ищется так же, как при переключении; сессия, которой ещё нет в конфиге, при добавлении
+// алиаса запоминается в нём. Повторное добавление того же алиаса и удаление отсутствующего ничего не меняют.
+func manageAlias(ts *tmuxState, action string, id string, alias string) {
+	if action != "add" && action != "remove" {
+		fatalf("unknown -alias action %s: use add or remove", action)
+	}
+	target, err := resolveTarget(ts, id, false)
+	if err != nil {
+		fatal(err)
+	}
+	fs := Config.Find(target.Name)
+
+	if action == "remove" {
+		if fs == nil {
+			fatalf("session %s is not in the config", target.Name)
+		}
+		for i, a := range fs.Aliases {
+			if a == alias {
+				fs.Aliases = append(fs.Aliases[:i], fs.Aliases[i+1:]...)
+				Config.changed = true
+				info("removed alias %s of %s", alias, fs.Name)
+				return
+			}
+		}
+		info("%s has no alias %s", target.Name, alias)
+		return
+	}
+
+	if alias == "" {
+		fatalf("alias must not be empty")
+	}
+	for _, other := range Config.Sessions {
+		if other.Name == target.Name {
+			continue
+		}
+		if other.Name == alias {
+			fatalf("cannot add alias %s: there is a session with this name", alias)
+		}
+		for _, a := range other.Aliases {
+			if a == alias {
+				fatalf("cannot add alias %s: it is already an alias of %s", alias, other.Name)
+			}
+		}
+	}
+	if fs == nil {
+		Config.Touch(target.Name, target.Path)
+		if Config.Find(target.Name) == nil {
+			fatalf("cannot add alias to %s: temporary sessions are not saved in the config", target.Name)
+		}
+	}
+	Config.AddAlias(target.Name, alias)
+	info("%s is now an alias of %s", alias, target.Name)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindAliasConflicts(t *testing.T) {
+	sessions := []FavouriteSession{
+		{Name: "api", Aliases: []string{"a", "api", "web", "a"}},
+		{Name: "web", Aliases: []string{"w", "a"}},
+		{Name: "db", Aliases: []string{"d"}},
+	}
+	want := []aliasConflict{
+		{Session: 0, Alias: 1, Reason: "same as the session name"},
+		{Session: 0, Alias: 2, Reason: "shadows session web"},
+		{Session: 0, Alias: 3, Reason: "duplicate alias"},
+		{Session: 1, Alias: 1, Reason: "already an alias of api"},
+	}
+	if got := findAliasConflicts(sessions); !reflect.DeepEqual(got, want) {
+		t.Errorf("findAliasConflicts() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFindAliasConflictsNone(t *testing.T) {
+	sessions := []FavouriteSession{
+		{Name: "api", Aliases: []string{"a"}},
+		{Name: "web", Aliases: []string{"w", "site"}},
+		{Name: "db"},
+	}
+	if got := findAliasConflicts(sessions); len(got) != 0 {
+		t.Errorf("findAliasConflicts() = %+v, want no conflicts", got)
+	}
+}
+
+func TestFindAliasConflictsShadowedLaterSession(t *testing.T) {
+	// алиас затеняет сессию, которая идёт в конфиге позже
+	sessions := []FavouriteSession{
+		{Name: "api", Aliases: []string{"db"}},
+		{Name: "db"},
+	}
+	want := []aliasConflict{{Session: 0, Alias: 0, Reason: "shadows session db"}}
+	if got := findAliasConflicts(sessions); !reflect.DeepEqual(got, want) {
+		t.Errorf("findAliasConflicts() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// autoClean завершает отсоединённые сессии, неактивные дольше maxAge.
+// Сессии, в каталоге которых есть незакоммиченные изменения, не трогаем.
+func autoClean(sessions []TmuxSession, maxAge time.Duration) {
+	toKill := []TmuxSession{}
+	for _, s := range sessions {
+		reason := ""
+		if s.Attached {
+			reason = "attached"
+		} else if s.LastActivity.IsZero() || time.Since(s.LastActivity) < maxAge {
+			reason = "recently active"
+		} else if dirty, _ := gitHasChanges(s.Path); dirty {
+			reason = "uncommitted changes in " + s.Path
+		}
+		if reason != "" {
+			fmt.Printf("spare %s: %s\n", s.Name, reason)
+			continue
+		}
+		toKill = append(toKill, s)
+	}
+
+	if len(toKill) == 0 {
+		fmt.Printf("nothing to clean\n")
+		return
+	}
+	for _, s := range toKill {
+		fmt.Printf("kill  %s: idle since %s\n", s.Name, s.FmtLastActivity())
+	}
+	if !confirm(fmt.Sprintf("kill %d session(s)?", len(toKill))) {
+		return
+	}
+	for _, s := range toKill {
+		if err := killSession(s.Name); err != nil {
+			log.Printf("%s", err)
+			continue
+		}
+		fmt.Printf("killed %s\n", s.Name)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// splitArgs разбивает строку команды на аргументы по пробелам; аргументы с пробелами
+// можно заключать в одинарные или двойные кавычки
+func splitArgs(line string) ([]string, error) {
+	args := []string{}
+	var cur strings.Builder
+	inArg := false
+	var quote rune
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// runBatchLine выполняет одну команду из -batch. Ошибка пользователя (fatalf) и паника
+// (например, из dieIfError) перехватываются и возвращаются как ошибка, чтобы выполнить остальные строки.
+func runBatchLine(args []string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	if err := flag.CommandLine.Parse(args); err != nil {
+		return err
+	}
+	if *fBatch != "" {
+		return fmt.Errorf("-batch cannot be nested")
+	}
+	run()
+	return nil
+}
+
+// runBatch выполняет команды pr из файла path (из stdin, если path равен "-"), по одной на строку.
+// Пустые строки и строки, начинающиеся с #, пропускаются; слово pr в начале строки можно не писать.
+// Флаги командной строки (например, -quiet или -dry-run) действуют на все строки.
+// Строка с ошибкой пропускается, остальные выполняются; конфиг со всеми изменениями
+// успешных строк записывается один раз в конце. Переключение на сессию вне tmux (attach)
+// в -batch не поддерживается: оно заменило бы процесс pr.
+func runBatch(path string) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			fatalf("cannot read batch file: %s", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	// значения флагов из командной строки, к которым они возвращаются перед каждой строкой
+	defaults := make(map[string]string)
+	flag.VisitAll(func(f *flag.Flag) {
+		defaults[f.Name] = f.Value.String()
+	})
+	defaults["batch"] = ""
+	// ошибка в флагах одной строки не должна завершать весь пакет; о ней сообщит runBatch
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+	flag.CommandLine.SetOutput(io.Discard)
+
+	Config.deferSave = true
+	inBatch = true
+	failed := 0
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		args, err := splitArgs(line)
+		if err == nil && len(args) > 0 && args[0] == "pr" {
+			args = args[1:]
+		}
+		if err == nil {
+			flag.VisitAll(func(f *flag.Flag) {
+				dieIfError(f.Value.Set(defaults[f.Name]))
+			})
+			log.SetPrefix(fmt.Sprintf("%s:%d: ", path, n))
+			err = runBatchLine(args)
+			log.SetPrefix("")
+		}
+		if err != nil {
+			failed++
+			log.Printf("line %d: %s: failed: %s", n, line, err)
+			continue
+		}
+		info("line %d: %s: ok", n, line)
+	}
+	inBatch = false
+	Config.deferSave = false
+	Config.Save()
+	dieIfError(scanner.Err())
+	if failed > 0 {
+		fatalf("%d batch commands failed", failed)
+	}
+}
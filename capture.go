@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// capturePane возвращает текст активной панели окна target (session или session:window)
+func capturePane(target string) []byte {
+	out, err := exec.Command("tmux", "capture-pane", "-p", "-t", target).CombinedOutput()
+	if err != nil {
+		fatalf("cannot capture %s: %s: %s", target, err, strings.TrimSpace(string(out)))
+	}
+	return out
+}
+
+// captureSession печатает содержимое текущей панели сессии (или активных панелей всех её окон,
+// если allWindows) либо сохраняет его в файл outFile
+func captureSession(ts *tmuxState, sessionId string, outFile string, allWindows bool) {
+	target, err := resolveTarget(ts, sessionId, false)
+	if err != nil {
+		fatal(err)
+	}
+	if _, live := ts.byName[target.Name]; !live {
+		fatalf("cannot capture %s: session is not running", target.Name)
+	}
+
+	var buf bytes.Buffer
+	if allWindows {
+		out, err := exec.Command("tmux", "list-windows", "-t", target.Name, "-F", "#{window_index}\t#{window_name}").CombinedOutput()
+		if err != nil {
+			fatalf("cannot list windows of %s: %s: %s", target.Name, err, strings.TrimSpace(string(out)))
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+			parts := strings.SplitN(line, "\t", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			fmt.Fprintf(&buf, "=== %s:%s (%s)\n", target.Name, parts[0], parts[1])
+			buf.Write(capturePane(target.Name + ":" + parts[0]))
+		}
+	} else {
+		buf.Write(capturePane(target.Name))
+	}
+
+	if outFile == "" {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	err = os.WriteFile(outFile, buf.Bytes(), 0644)
+	dieIfError(err)
+	info("saved %s to %s", target.Name, outFile)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// tmuxWindow это окно живой сессии tmux
+type tmuxWindow struct {
+	Name     string
+	Path     string
+	StartCmd string // команда, с которой был запущен первый pane окна (пусто для shell по умолчанию)
+}
+
+// listWindows возвращает окна указанной сессии
+func listWindows(session string) ([]tmuxWindow, error) {
+	out, err := exec.Command("tmux", "list-windows", "-t", session, "-F", "#{window_name}\t#{pane_current_path}\t#{pane_start_command}").Output()
+	if err != nil {
+		return nil, err
+	}
+	windows := []tmuxWindow{}
+	for _, line := range strings.Split(string(out), "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) == 3 {
+			startCmd := parts[2]
+			if unquoted, err := strconv.Unquote(startCmd); err == nil {
+				// tmux берёт команду из одного аргумента в кавычки
+				startCmd = unquoted
+			}
+			windows = append(windows, tmuxWindow{Name: parts[0], Path: parts[1], StartCmd: startCmd})
+		}
+	}
+	return windows, nil
+}
+
+// cloneSession создаёт копию живой сессии: с тем же каталогом и окнами (имена, каталоги, стартовые команды),
+// и переключается на неё. Раскладка pane'ов не копируется.
+func cloneSession(ts *tmuxState, sessionId string) {
+	src, ok := findLiveSession(ts.sessions, sessionId)
+	if !ok {
+		fatalf("session %s not found", sessionId)
+	}
+	windows, err := listWindows(src.Name)
+	dieIfError(err)
+
+	name := freeSessionName(ts.byName, src.Name)
+
+	for i, w := range windows {
+		var args []string
+		if i == 0 {
+			args = []string{"new", "-d", "-s", name, "-c", src.Path, "-n", w.Name}
+		} else {
+			args = []string{"new-window", "-d", "-t", name + ":", "-c", w.Path, "-n", w.Name}
+		}
+		if w.StartCmd != "" {
+			args = append(args, w.StartCmd)
+		}
+		out, err := exec.Command("tmux", args...).CombinedOutput()
+		if err != nil {
+			log.Printf("cannot recreate window %s: %s", w.Name, strings.TrimSpace(string(out)))
+			if i == 0 {
+				dieIfError(err)
+			}
+		}
+	}
+	touchSession(name, src.Path)
+	switchToSession(name)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// commandLogFilename возвращает файл журнала команд сессии: <каталог конфига>/logs/<сессия>.log
+func commandLogFilename(session string) string {
+	name := strings.ReplaceAll(session, string(filepath.Separator), "_")
+	return filepath.Join(filepath.Dir(ConfigPath), "logs", name+".log")
+}
+
+// logCommand дописывает выполненную для сессии команду tmux и её результат в журнал сессии,
+// если в конфиге включён log_commands. Ошибки записи журнала не мешают работе pr.
+func logCommand(session string, args []string, err error, out []byte) {
+	if !Config.LogCommands {
+		return
+	}
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t\n\"'\\$;&|<>") {
+			a = strconv.Quote(a)
+		}
+		quoted[i] = a
+	}
+	line := fmt.Sprintf("%s tmux %s", time.Now().Format(time.RFC3339), strings.Join(quoted, " "))
+	if err != nil {
+		line += fmt.Sprintf(" # failed: %s", err)
+		if o := strings.TrimSpace(string(out)); o != "" {
+			line += ": " + o
+		}
+	}
+
+	filename := commandLogFilename(session)
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		warn("cannot write command log: %s", err)
+		return
+	}
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		warn("cannot write command log: %s", err)
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, line)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// zshCompletion это скрипт автодополнения для zsh. Имена сессий берутся
+// из pr -complete -complete-descriptions, флаги подставляются при генерации скрипта.
+// Скрипт работает и как файл _pr в $fpath (zsh выполняет тело файла как функцию _pr,
+// поэтому дополнение нужно выполнить сразу), и через source (тогда функция регистрируется compdef).
+const zshCompletion = `#compdef pr
+
+_pr() {
+	if [[ $PREFIX == -* ]]; then
+		local -a flags
+		flags=(
+%s		)
+		_describe 'flag' flags
+		return
+	fi
+	local -a candidates
+	candidates=("${(@f)$(pr -quiet -complete -complete-descriptions 2>/dev/null)}")
+	_describe 'project' candidates
+}
+
+if [[ $funcstack[1] == _pr ]]; then
+	_pr "$@"
+else
+	compdef _pr pr
+fi
+`
+
+// bashCompletion это скрипт автодополнения для bash. Имена сессий берутся из pr -complete
+const bashCompletion = `_pr() {
+	local cur="${COMP_WORDS[COMP_CWORD]}"
+	if [[ "$cur" == -* ]]; then
+		COMPREPLY=($(compgen -W "%s" -- "$cur"))
+	else
+		COMPREPLY=($(compgen -W "$(pr -quiet -complete 2>/dev/null)" -- "$cur"))
+	fi
+}
+
+complete -F _pr pr
+`
+
+// printCompletionScript печатает скрипт автодополнения для shell (bash или zsh)
+func printCompletionScript(shell string) {
+	switch shell {
+	case "bash":
+		names := []string{}
+		flag.VisitAll(func(f *flag.Flag) {
+			names = append(names, "-"+f.Name)
+		})
+		fmt.Printf(bashCompletion, strings.Join(names, " "))
+	case "zsh":
+		var b strings.Builder
+		flag.VisitAll(func(f *flag.Flag) {
+			usage := strings.NewReplacer(":", `\:`, "'", "'\\''").Replace(f.Usage)
+			fmt.Fprintf(&b, "\t\t\t'-%s:%s'\n", f.Name, usage)
+		})
+		fmt.Printf(zshCompletion, b.String())
+	default:
+		fatalf("unknown shell %s: use bash or zsh", shell)
+	}
+}
+
+// printCompletions печатает идентификаторы, которые можно передать pr: имена живых сессий,
+// имена и алиасы сессий из конфига и недавние каталоги. С descriptions печатает пары "имя:описание"
+// (каталог и состояние сессии) в формате _describe из zsh; bash их не поддерживает.
+func printCompletions(sessions []TmuxSession, descriptions bool) {
+	seen := make(map[string]bool)
+	seenPaths := make(map[string]bool)
+	add := func(name string, path string, state string) {
+		seenPaths[normalizePath(path)] = true
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		if !descriptions {
+			fmt.Println(name)
+			return
+		}
+		fmt.Printf("%s:%s (%s)\n", strings.ReplaceAll(name, ":", `\:`), path, state)
+	}
+
+	for _, s := range sessions {
+		state := "live"
+		if s.Attached {
+			state = "attached"
+		}
+		add(s.Name, s.Path, state)
+	}
+	for _, fs := range Config.Sessions {
+		add(fs.Name, fs.Path, "saved")
+		for _, a := range fs.Aliases {
+			add(a, fs.Path, "alias of "+fs.Name)
+		}
+	}
+	// недавние каталоги тоже открываются по имени, если для них ещё нет ни сессии, ни проекта
+	for _, d := range readRecentDirs() {
+		if !seenPaths[normalizePath(d)] {
+			add(filepath.Base(d), d, "recent")
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSetRoot(t *testing.T) {
+	home := t.TempDir()
+	withHome(t, home)
+	newDir := filepath.Join(home, "new")
+	if err := os.Mkdir(newDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	fc := FavouritesConfig{Sessions: []FavouriteSession{
+		{Name: "api", Path: "/old/api", Cmd: "make run", Aliases: []string{"a", "backend"}, Env: map[string]string{"PORT": "8080"}},
+	}}
+
+	if err := fc.SetRoot("backend", "~/new"); err != nil {
+		t.Fatalf("SetRoot(alias) error: %s", err)
+	}
+	want := FavouriteSession{Name: "api", Path: newDir, Cmd: "make run", Aliases: []string{"a", "backend"}, Env: map[string]string{"PORT": "8080"}}
+	if !reflect.DeepEqual(fc.Sessions[0], want) {
+		t.Errorf("after SetRoot session = %+v, want %+v", fc.Sessions[0], want)
+	}
+	if !fc.changed {
+		t.Error("SetRoot did not mark config as changed")
+	}
+
+	fc.changed = false
+	if err := fc.SetRoot("api", newDir); err != nil || fc.changed {
+		t.Errorf("SetRoot(same path) = %v, changed %t, want no change", err, fc.changed)
+	}
+}
+
+func TestSetRootErrors(t *testing.T) {
+	withHome(t, t.TempDir())
+	fc := FavouritesConfig{Sessions: []FavouriteSession{{Name: "api", Path: "/old/api"}}}
+	if err := fc.SetRoot("web", "/tmp"); err == nil {
+		t.Error("SetRoot(unknown session) succeeded, want error")
+	}
+	if err := fc.SetRoot("api", "~/missing"); err == nil {
+		t.Error("SetRoot(missing dir) succeeded, want error")
+	}
+	if fc.Sessions[0].Path != "/old/api" || fc.changed {
+		t.Errorf("failed SetRoot changed the config: %+v", fc.Sessions[0])
+	}
+}
+
+func TestRecordLastAttached(t *testing.T) {
+	fc := FavouritesConfig{}
+
+	fc.RecordLastAttached("api", "web")
+	if fc.LastAttached != "api" || !fc.changed {
+		t.Errorf("api -> web: last_attached = %q, changed %t, want api, true", fc.LastAttached, fc.changed)
+	}
+
+	// переход обратно: pr -b снова вернёт на web
+	fc.RecordLastAttached("web", "api")
+	if fc.LastAttached != "web" {
+		t.Errorf("web -> api: last_attached = %q, want web", fc.LastAttached)
+	}
+
+	fc.changed = false
+	fc.RecordLastAttached("api", "api")
+	if fc.LastAttached != "web" || fc.changed {
+		t.Errorf("api -> api: last_attached = %q, changed %t, want web, false", fc.LastAttached, fc.changed)
+	}
+	fc.RecordLastAttached("", "db")
+	if fc.LastAttached != "web" || fc.changed {
+		t.Errorf("unknown -> db: last_attached = %q, changed %t, want web, false", fc.LastAttached, fc.changed)
+	}
+	fc.RecordLastAttached("web", "db")
+	if fc.LastAttached != "web" || fc.changed {
+		t.Errorf("web -> db with web recorded: changed %t, want false", fc.changed)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// diffLines сравнивает строки old и new (через наибольшую общую подпоследовательность)
+// и возвращает изменения: удалённые строки с префиксом "-", добавленные - с "+"
+func diffLines(old []string, new []string) []string {
+	// lcs[i][j] - длина общей подпоследовательности old[i:] и new[j:]
+	lcs := make([][]int, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(new)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(new) - 1; j >= 0; j-- {
+			if old[i] == new[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	result := []string{}
+	i, j := 0, 0
+	for i < len(old) || j < len(new) {
+		switch {
+		case i < len(old) && j < len(new) && old[i] == new[j]:
+			i++
+			j++
+		case j < len(new) && (i == len(old) || lcs[i][j+1] >= lcs[i+1][j]):
+			result = append(result, "+"+new[j])
+			j++
+		default:
+			result = append(result, "-"+old[i])
+			i++
+		}
+	}
+	return result
+}
+
+// printConfigDiff печатает разницу между конфигом на диске и сериализованным конфигом bs
+func printConfigDiff(bs []byte) {
+	onDisk, err := os.ReadFile(ConfigPath)
+	if err != nil && !os.IsNotExist(err) {
+		dieIfError(err)
+	}
+	split := func(b []byte) []string {
+		s := strings.TrimRight(string(b), "\n")
+		if s == "" {
+			return []string{}
+		}
+		return strings.Split(s, "\n")
+	}
+	fmt.Printf("--- %s\n+++ %s (pending)\n", ConfigPath, ConfigPath)
+	for _, line := range diffLines(split(onDisk), split(bs)) {
+		if strings.HasPrefix(line, "+") {
+			color.Green("%s", line)
+		} else {
+			color.Red("%s", line)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configFormat это формат файла конфига. У полей конфига одинаковые json- и yaml-теги:
+// YAML записывается через JSON (чтобы вывод совпадал по ключам и порядку), а читается
+// напрямую в структуру, чтобы незакавыченные числа и true/false в строковых полях
+// (например, env: {PORT: 8080}) читались как строки.
+type configFormat interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// configFormatFor выбирает формат конфига по расширению файла
+func configFormatFor(path string) configFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return yamlConfigFormat{}
+	}
+	return jsonConfigFormat{}
+}
+
+type jsonConfigFormat struct{}
+
+func (jsonConfigFormat) Marshal(v interface{}) ([]byte, error) {
+	return json.MarshalIndent(v, "", "    ")
+}
+
+func (jsonConfigFormat) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type yamlConfigFormat struct{}
+
+func (yamlConfigFormat) Marshal(v interface{}) ([]byte, error) {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	// JSON является корректным YAML: разбираем его в дерево, сохраняя порядок ключей,
+	// и сбрасываем стиль узлов, чтобы вывести блочный YAML вместо {...} и "..."
+	var node yaml.Node
+	if err := yaml.Unmarshal(bs, &node); err != nil {
+		return nil, err
+	}
+	resetYamlStyle(&node)
+	return yaml.Marshal(&node)
+}
+
+func (yamlConfigFormat) Unmarshal(data []byte, v interface{}) error {
+	return yaml.Unmarshal(data, v)
+}
+
+// resetYamlStyle сбрасывает стиль узла и всех его потомков на стиль по умолчанию
+func resetYamlStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYamlStyle(child)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func sampleConfig() FavouritesConfig {
+	lastUsed := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	weight := 0.5
+	autoCreate := false
+	return FavouritesConfig{
+		Sessions: []FavouriteSession{
+			{
+				Name:    "api",
+				Path:    "~/work/api",
+				Cmd:     "make run",
+				Aliases: []string{"a"},
+				Env:     map[string]string{"PORT": "8080", "DEBUG": "true"},
+				Layout: []LayoutWindow{
+					{Window: "edit", Cmd: "vim"},
+					{Window: "logs", Dir: "log", Cmd: "tail -f app.log"},
+				},
+				DefaultWindow: 1,
+				LastUsed:      &lastUsed,
+			},
+		},
+		FuzzyRecencyWeight: &weight,
+		AutoCreateTmp:      &autoCreate,
+		PinnedSessions:     []string{"api"},
+		Roots:              []string{"~/work", "$HOME/src"},
+		ProjectTypes:       map[string]ProjectType{"go": {Cmd: "go build ./...", Env: map[string]string{"CGO_ENABLED": "0"}}},
+	}
+}
+
+func TestConfigFormatRoundTrip(t *testing.T) {
+	for _, path := range []string{"pr.json", "pr.yaml", "pr.yml"} {
+		format := configFormatFor(path)
+		original := sampleConfig()
+		bs, err := format.Marshal(&original)
+		if err != nil {
+			t.Fatalf("%s: marshal: %s", path, err)
+		}
+		var loaded FavouritesConfig
+		if err := format.Unmarshal(bs, &loaded); err != nil {
+			t.Fatalf("%s: unmarshal: %s\n%s", path, err, bs)
+		}
+		if !reflect.DeepEqual(loaded, original) {
+			t.Errorf("%s: round trip changed config:\ngot  %+v\nwant %+v\n%s", path, loaded, original, bs)
+		}
+	}
+}
+
+func TestYamlConfigUnquotedScalars(t *testing.T) {
+	data := []byte(`
+sessions:
+  - name: api
+    path: ~/work/api
+    env:
+      PORT: 8080
+      DEBUG: true
+      RATIO: 1.5
+auto_create_tmp: false
+fuzzy_recency_weight: 2
+`)
+	var fc FavouritesConfig
+	if err := configFormatFor("pr.yaml").Unmarshal(data, &fc); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"PORT": "8080", "DEBUG": "true", "RATIO": "1.5"}
+	if len(fc.Sessions) != 1 || !reflect.DeepEqual(fc.Sessions[0].Env, want) {
+		t.Errorf("env = %+v, want %+v", fc.Sessions, want)
+	}
+	if fc.AutoCreateTmp == nil || *fc.AutoCreateTmp {
+		t.Errorf("auto_create_tmp = %v, want false", fc.AutoCreateTmp)
+	}
+	if fc.RecencyWeight() != 2 {
+		t.Errorf("fuzzy_recency_weight = %v, want 2", fc.RecencyWeight())
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// detachSessionById отключает от сессии, найденной так же, как при переключении, все подключённые клиенты,
+// чтобы её можно было занять (например, если она осталась открытой на другой машине)
+func detachSessionById(ts *tmuxState, id string) {
+	s, err := resolveLiveSession(ts, id)
+	if err != nil {
+		fatal(err)
+	}
+	if !s.Attached {
+		info("session %s is not attached anywhere", s.Name)
+		return
+	}
+	out, err := exec.Command("tmux", "detach-client", "-s", "="+s.Name).CombinedOutput()
+	if err != nil {
+		fatalf("cannot detach clients from %s: %s: %s", s.Name, err, strings.TrimSpace(string(out)))
+	}
+	info("detached all clients from %s", s.Name)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+)
+
+// manageEnv задаёт (action "set", arg KEY=VALUE) или удаляет (action "unset", arg KEY)
+// переменную окружения сессии из конфига. Сессия ищется так же, как при переключении;
+// сессия, которой ещё нет в конфиге, при set запоминается в нём. Удаление отсутствующей
+// переменной ничего не меняет. Переменные применяются при следующем создании сессии.
+func manageEnv(ts *tmuxState, action string, id string, arg string) {
+	if action != "set" && action != "unset" {
+		fatalf("unknown -env action %s: use set or unset", action)
+	}
+	target, err := resolveTarget(ts, id, false)
+	if err != nil {
+		fatal(err)
+	}
+	fs := Config.Find(target.Name)
+
+	if action == "unset" {
+		if fs == nil {
+			fatalf("session %s is not in the config", target.Name)
+		}
+		if _, ok := fs.Env[arg]; !ok {
+			info("%s has no env %s", fs.Name, arg)
+			return
+		}
+		delete(fs.Env, arg)
+		Config.changed = true
+		info("removed env %s of %s", arg, fs.Name)
+		return
+	}
+
+	key, value, ok := strings.Cut(arg, "=")
+	if !ok || key == "" {
+		fatalf("usage: pr -env set <session> KEY=VALUE")
+	}
+	if !envNameRe.MatchString(key) {
+		fatalf("invalid environment variable name %q", key)
+	}
+	if fs == nil {
+		Config.Touch(target.Name, target.Path)
+		fs = Config.Find(target.Name)
+		if fs == nil {
+			fatalf("cannot set env of %s: temporary sessions are not saved in the config", target.Name)
+		}
+	}
+	if fs.Env == nil {
+		fs.Env = make(map[string]string)
+	}
+	fs.Env[key] = value
+	Config.changed = true
+	info("set %s=%s for %s", key, value, fs.Name)
+}
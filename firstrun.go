@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// shellIntegration возвращает строки для конфига shell, записывающие посещённые каталоги (см. -record-dir)
+func shellIntegration(shell string) (string, string) {
+	switch shell {
+	case "zsh":
+		return "~/.zshrc", `chpwd() { pr -record-dir "$PWD" }`
+	case "bash":
+		return "~/.bashrc", `PROMPT_COMMAND="pr -record-dir \"\$PWD\"; $PROMPT_COMMAND"`
+	case "fish":
+		return "~/.config/fish/config.fish", `function __pr_record --on-variable PWD; pr -record-dir "$PWD"; end`
+	}
+	return "", ""
+}
+
+// askDefault задаёт вопрос с ответом по умолчанию, который используется при пустом вводе
+func askDefault(question string, defaultAnswer string) string {
+	fmt.Printf("%s [%s]: ", question, defaultAnswer)
+	answer := strings.TrimSpace(readLine())
+	if answer == "" {
+		return defaultAnswer
+	}
+	return answer
+}
+
+// firstRun проводит начальную настройку: подсказывает интеграцию с shell и tmux,
+// спрашивает каталог с проектами (запоминая его в roots), предлагает добавить его подкаталоги
+// в конфиг и сохраняет конфиг.
+// Без терминала на stdin ничего не делает.
+func firstRun() {
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return
+	}
+	fmt.Printf("Setting up pr (config: %s)\n\n", ConfigPath)
+
+	shell := filepath.Base(os.Getenv("SHELL"))
+	if rc, line := shellIntegration(shell); rc != "" {
+		fmt.Printf("To find projects among recently visited directories, add to %s:\n  %s\n\n", rc, line)
+	}
+	fmt.Printf("To open pr in a popup, add to ~/.tmux.conf:\n  bind P display-popup -E -E \"pr -fzf\"\n\n")
+
+	root := expandHome(askDefault("directory with your projects", "~"))
+	if !isDir(root) {
+		fmt.Printf("%s does not exist, skipping import\n", root)
+	} else if entries, err := os.ReadDir(root); err == nil {
+		if Config.AddRoot(root) {
+			fmt.Printf("added %s to roots: pr will look for projects there by name\n", root)
+		}
+		dirs := []string{}
+		for _, e := range entries {
+			if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+				dirs = append(dirs, e.Name())
+			}
+		}
+		if len(dirs) > 0 && confirm(fmt.Sprintf("add %d directories from %s as saved sessions (%s)?", len(dirs), root, strings.Join(dirs, ", "))) {
+			for _, d := range dirs {
+				if Config.Find(d) != nil {
+					continue
+				}
+				Config.Sessions = append(Config.Sessions, FavouriteSession{
+					Name:    d,
+					Path:    filepath.Join(root, d),
+					Aliases: []string{},
+					Env:     make(map[string]string),
+				})
+			}
+		}
+	}
+	Config.changed = true
+	Config.Save()
+	fmt.Printf("\nsaved %s, edit it with pr -edit\n", ConfigPath)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// formattedSession содержит поля, доступные в шаблоне -format
+type formattedSession struct {
+	Name         string
+	Path         string
+	Windows      int
+	Attached     bool
+	LastActivity time.Time
+	Live         bool // сессия запущена в tmux (а не взята из конфига или недавних каталогов)
+}
+
+// printFormatted выводит список allSessions, применяя к каждой сессии шаблон text/template.
+// live - список запущенных сессий tmux.
+func printFormatted(live []TmuxSession, allSessions []TmuxSession, format string) {
+	// в командной строке удобнее писать \t и \n, чем настоящие табуляции и переводы строк
+	format = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(format)
+	tmpl, err := template.New("format").Parse(format + "\n")
+	if err != nil {
+		fatalf("invalid -format template: %s", err)
+	}
+
+	liveNames := make(map[string]bool)
+	for _, s := range live {
+		liveNames[s.Name] = true
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	for _, s := range allSessions {
+		fs := formattedSession{
+			Name:         s.Name,
+			Path:         s.Path,
+			Windows:      s.WindowsCount,
+			Attached:     s.Attached,
+			LastActivity: s.LastActivity,
+			Live:         liveNames[s.Name],
+		}
+		if err := tmpl.Execute(w, fs); err != nil {
+			w.Flush()
+			fatalf("cannot execute -format template for session %s: %s", s.Name, err)
+		}
+	}
+}
+
+// jsonSession это сессия в выводе списка с -json
+type jsonSession struct {
+	Name         string  `json:"name"`
+	Path         string  `json:"path"`
+	Windows      int     `json:"windows"`
+	Attached     bool    `json:"attached"`
+	LastActivity *string `json:"last_activity"` // RFC3339; null для сессий, о которых ничего не известно
+	Todo         *string `json:"todo,omitempty"`
+}
+
+// newJsonSession готовит сессию к выводу в JSON, с withTodo - вместе с содержимым .todo
+func newJsonSession(s TmuxSession, withTodo bool) jsonSession {
+	js := jsonSession{
+		Name:     s.Name,
+		Path:     s.Path,
+		Windows:  s.WindowsCount,
+		Attached: s.Attached,
+	}
+	if !s.LastActivity.IsZero() {
+		t := s.LastActivity.Format(time.RFC3339)
+		js.LastActivity = &t
+	}
+	if withTodo {
+		todo := getTodoContents(s.Path)
+		js.Todo = &todo
+	}
+	return js
+}
+
+// printSessionsJson выводит список сессий массивом JSON (без цветов), с withTodo - и содержимое .todo
+func printSessionsJson(allSessions []TmuxSession, withTodo bool) {
+	result := make([]jsonSession, 0, len(allSessions))
+	for _, s := range allSessions {
+		result = append(result, newJsonSession(s, withTodo))
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "    ")
+	dieIfError(enc.Encode(result))
+}
+
+// printSessionsJsonLines выводит каждую сессию отдельной строкой JSON (NDJSON),
+// так что вывод можно обрабатывать построчно, не дожидаясь конца списка
+func printSessionsJsonLines(allSessions []TmuxSession, withTodo bool) {
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	enc := json.NewEncoder(w)
+	for _, s := range allSessions {
+		dieIfError(enc.Encode(newJsonSession(s, withTodo)))
+	}
+}
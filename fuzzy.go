@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// fuzzyMatch это сессия, подходящая под нечёткий запрос, и её оценка
+type fuzzyMatch struct {
+	Session TmuxSession
+	Score   float64
+}
+
+// fuzzyScore оценивает, насколько candidate подходит под query как подпоследовательность символов
+// (без учёта регистра). Возвращает 0, если символы query не встречаются в candidate по порядку.
+// Бонусы начисляются за совпадение в начале строки, после разделителя и за подряд идущие символы.
+func fuzzyScore(query string, candidate string) int {
+	q := []rune(strings.ToLower(query))
+	c := []rune(strings.ToLower(candidate))
+	if len(q) == 0 {
+		return 0
+	}
+	score := 0
+	qi := 0
+	prevMatched := -2
+	for ci := 0; ci < len(c) && qi < len(q); ci++ {
+		if c[ci] != q[qi] {
+			continue
+		}
+		score++
+		if ci == 0 {
+			score += 3
+		} else if strings.ContainsRune("-_./ ", c[ci-1]) {
+			score += 2
+		}
+		if prevMatched == ci-1 {
+			score += 2
+		}
+		prevMatched = ci
+		qi++
+	}
+	if qi < len(q) {
+		return 0
+	}
+	return score
+}
+
+// rankFuzzy возвращает сессии, подходящие под query, от лучшей к худшей.
+// candidates должны быть упорядочены от недавних к давним: к оценке добавляется
+// бонус recencyWeight/(1+позиция), поэтому при равных оценках побеждает более свежая сессия.
+func rankFuzzy(query string, candidates []TmuxSession, recencyWeight float64) []fuzzyMatch {
+	matches := []fuzzyMatch{}
+	for i, s := range candidates {
+		score := fuzzyScore(query, s.Name)
+		if score == 0 {
+			continue
+		}
+		matches = append(matches, fuzzyMatch{
+			Session: s,
+			Score:   float64(score) + recencyWeight/float64(1+i),
+		})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
+
+// fuzzyResolve ищет среди живых сессий и сессий из конфига (по именам и алиасам) единственную,
+// нечётко подходящую под query. Кандидаты должны набрать не меньше двух очков на символ запроса.
+// Если лучших кандидатов несколько, возвращает ошибку с их перечислением.
+// Второе значение false, если не подошёл ни один кандидат.
+func fuzzyResolve(sessions []TmuxSession, query string) (sessionTarget, bool, error) {
+	threshold := 2 * len([]rune(query))
+	best := 0
+	bestTargets := []sessionTarget{}
+	consider := func(name string, t sessionTarget) {
+		score := fuzzyScore(query, name)
+		if score < threshold || score < best {
+			return
+		}
+		for _, bt := range bestTargets {
+			if bt.Name == t.Name && score == best {
+				return
+			}
+		}
+		if score > best {
+			best = score
+			bestTargets = nil
+		}
+		bestTargets = append(bestTargets, t)
+	}
+	for _, s := range sessions {
+		consider(s.Name, sessionTarget{Name: s.Name, Path: s.Path})
+	}
+	for _, fs := range Config.Sessions {
+		t := sessionTarget{
+			Name:          fs.Name,
+			Path:          fs.ExpandedPath(),
+			StartCmd:      fs.Cmd,
+			Env:           fs.Env,
+			Layout:        fs.Layout,
+			DefaultWindow: fs.DefaultWindow,
+		}
+		consider(fs.Name, t)
+		for _, a := range fs.Aliases {
+			consider(a, t)
+		}
+	}
+
+	switch len(bestTargets) {
+	case 0:
+		return sessionTarget{}, false, nil
+	case 1:
+		return bestTargets[0], true, nil
+	}
+	names := []string{}
+	for _, t := range bestTargets {
+		names = append(names, t.Name)
+	}
+	return sessionTarget{}, true, fmt.Errorf("%s is ambiguous, similar sessions: %s", query, strings.Join(names, ", "))
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// chooseWithFzf показывает живые и сохранённые сессии в fzf и возвращает имя выбранной сессии.
+// Если пользователь ничего не выбрал, возвращает пустую строку.
+func chooseWithFzf(sessions []TmuxSession) string {
+	var input bytes.Buffer
+	for _, s := range withSavedSessions(sessions) {
+		fmt.Fprintf(&input, "%s\t%s\n", s.Name, s.Path)
+	}
+
+	cmd := exec.Command("fzf", "--delimiter", "\t", "--prompt", "project> ")
+	cmd.Stdin = &input
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && (exitErr.ExitCode() == 1 || exitErr.ExitCode() == 130) {
+			// 1: нет совпадений, 130: выбор отменён
+			return ""
+		}
+		fatalf("fzf failed: %s", err)
+	}
+
+	line := strings.TrimRight(string(out), "\n")
+	name, _, _ := strings.Cut(line, "\t")
+	return name
+}
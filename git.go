@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// gitHasChanges возвращает true, если в git-репозитории, содержащем dir, есть незакоммиченные изменения.
+// Второе значение false, если dir не находится внутри git-репозитория.
+func gitHasChanges(dir string) (bool, bool) {
+	out, err := exec.Command("git", "-C", dir, "status", "--porcelain").Output()
+	if err != nil {
+		return false, false
+	}
+	return strings.TrimSpace(string(out)) != "", true
+}
+
+// gitIsTracked возвращает true, если path находится под контролем git: внутри рабочей копии
+// и содержит отслеживаемые файлы. Каталог самого репозитория (с .git внутри) не считается
+// отслеживаемым: его нужно переносить целиком.
+func gitIsTracked(path string) bool {
+	parent := filepath.Dir(path)
+	if err := exec.Command("git", "-C", parent, "rev-parse", "--is-inside-work-tree").Run(); err != nil {
+		return false
+	}
+	out, err := exec.Command("git", "-C", parent, "ls-files", "--", filepath.Base(path)).Output()
+	return err == nil && strings.TrimSpace(string(out)) != ""
+}
+
+// maxGitStatusLines это число строк git status, показываемых в кратком описании репозитория
+const maxGitStatusLines = 5
+
+// gitSummary возвращает ветку и начало git status --short для каталога dir
+// или пустую строку, если dir не находится внутри git-репозитория
+func gitSummary(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	summary := "branch " + strings.TrimSpace(string(out))
+	out, err = exec.Command("git", "-C", dir, "status", "--short").Output()
+	if err != nil {
+		return summary
+	}
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return summary + ", clean"
+	}
+	summary += fmt.Sprintf(", %d changed", len(lines))
+	if len(lines) > maxGitStatusLines {
+		lines = append(lines[:maxGitStatusLines], "...")
+	}
+	return summary + "\n" + strings.Join(lines, "\n")
+}
+
+// gitBranch возвращает текущую ветку репозитория, содержащего dir. Для отсоединённого HEAD
+// возвращает короткий хэш коммита. Второе значение false, если dir не находится внутри репозитория.
+func gitBranch(dir string) (string, bool) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return "", false
+	}
+	branch := strings.TrimSpace(string(out))
+	if branch == "HEAD" {
+		out, err = exec.Command("git", "-C", dir, "rev-parse", "--short", "HEAD").Output()
+		if err != nil {
+			return "", false
+		}
+		branch = strings.TrimSpace(string(out))
+	}
+	return branch, true
+}
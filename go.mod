@@ -4,11 +4,14 @@ go 1.20
 
 require (
 	github.com/fatih/color v1.15.0
+	github.com/mattn/go-isatty v0.0.17
+	github.com/mattn/go-runewidth v0.0.15
 	github.com/rodaine/table v1.1.0
+	golang.org/x/sys v0.6.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
-	github.com/mattn/go-isatty v0.0.17 // indirect
-	golang.org/x/sys v0.6.0 // indirect
+	github.com/rivo/uniseg v0.2.0 // indirect
 )
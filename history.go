@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+)
+
+// maxHistory это максимальное число сессий в истории переключений (history в конфиге)
+const maxHistory = 100
+
+// pushHistory переносит сессию в конец истории переключений (самые новые в конце)
+func (fc *FavouritesConfig) pushHistory(name string) {
+	history := make([]string, 0, len(fc.History)+1)
+	for _, h := range fc.History {
+		if h != name {
+			history = append(history, h)
+		}
+	}
+	history = append(history, name)
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+	fc.History = history
+	fc.changed = true
+}
+
+// recordSwitch добавляет сессию, на которую переключается клиент, в историю переключений.
+// С -no-touch (в том числе при -prev/-next) история не меняется, иначе повторный -prev вернул бы обратно.
+func recordSwitch(name string) {
+	if *fNoTouch {
+		return
+	}
+	Config.pushHistory(name)
+}
+
+// historyStep возвращает сессию, предшествующую текущей в истории переключений
+// (или следующую за ней, если forward). Сессии, которых нет в tmux, пропускаются.
+// Вне tmux шаг назад ведёт к последней сессии в истории.
+func historyStep(ts *tmuxState, forward bool) string {
+	current := len(Config.History)
+	if os.Getenv("TMUX") != "" {
+		name := getCurrentSessionName()
+		for i, h := range Config.History {
+			if h == name {
+				current = i
+			}
+		}
+	}
+	step := -1
+	if forward {
+		step = 1
+	}
+	for i := current + step; i >= 0 && i < len(Config.History); i += step {
+		if _, ok := ts.byName[Config.History[i]]; ok {
+			return Config.History[i]
+		}
+	}
+	if forward {
+		fatalf("no next session in history")
+	}
+	fatalf("no previous session in history")
+	return ""
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTouchDoesNotChangeHistory(t *testing.T) {
+	withConfig(t, FavouritesConfig{History: []string{"api"}})
+	Config.Touch("web", "/src/web")
+	if !reflect.DeepEqual(Config.History, []string{"api"}) {
+		t.Errorf("History after Touch = %q, want [api]: only switches go to history", Config.History)
+	}
+}
+
+func TestRecordSwitch(t *testing.T) {
+	withConfig(t, FavouritesConfig{History: []string{"api", "web", "db"}})
+	old := *fNoTouch
+	t.Cleanup(func() { *fNoTouch = old })
+
+	*fNoTouch = false
+	recordSwitch("web")
+	if want := []string{"api", "db", "web"}; !reflect.DeepEqual(Config.History, want) {
+		t.Errorf("History = %q, want %q", Config.History, want)
+	}
+
+	*fNoTouch = true
+	recordSwitch("api")
+	if want := []string{"api", "db", "web"}; !reflect.DeepEqual(Config.History, want) {
+		t.Errorf("History with -no-touch = %q, want %q", Config.History, want)
+	}
+}
+
+func TestPushHistoryLimit(t *testing.T) {
+	fc := FavouritesConfig{}
+	for i := 0; i < maxHistory+5; i++ {
+		fc.pushHistory(suffixedName("s", i))
+	}
+	if len(fc.History) != maxHistory || fc.History[len(fc.History)-1] != suffixedName("s", maxHistory+4) {
+		t.Errorf("History has %d entries ending with %q, want %d ending with the last push", len(fc.History), fc.History[len(fc.History)-1], maxHistory)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sessionInfo это сведения о сессии из tmux и из конфига вместе
+type sessionInfo struct {
+	Name         string            `json:"name"`
+	Path         string            `json:"path"`
+	Live         bool              `json:"live"`
+	Attached     bool              `json:"attached"`
+	Windows      int               `json:"windows"`
+	LastActivity *time.Time        `json:"last_activity"`
+	Saved        bool              `json:"saved"`
+	Cmd          string            `json:"cmd,omitempty"`
+	Aliases      []string          `json:"aliases,omitempty"`
+	Env          map[string]string `json:"env,omitempty"`
+}
+
+// getSessionInfo собирает сведения о сессии, найденной по идентификатору так же, как в ChangeSession
+func getSessionInfo(ts *tmuxState, sessionId string) (sessionInfo, error) {
+	target, err := resolveTarget(ts, sessionId, false)
+	if err != nil {
+		return sessionInfo{}, err
+	}
+	si := sessionInfo{
+		Name: target.Name,
+		Path: target.Path,
+	}
+	if s, ok := ts.byName[target.Name]; ok {
+		si.Live = true
+		si.Attached = s.Attached
+		si.Windows = s.WindowsCount
+		if !s.LastActivity.IsZero() {
+			t := s.LastActivity
+			si.LastActivity = &t
+		}
+	}
+	if fs := Config.Find(target.Name); fs != nil {
+		si.Saved = true
+		si.Cmd = fs.Cmd
+		si.Aliases = fs.Aliases
+		si.Env = fs.Env
+	}
+	return si, nil
+}
+
+// printSessionInfo выводит сведения о сессии в виде списка полей или в JSON
+func printSessionInfo(ts *tmuxState, sessionId string, asJson bool) {
+	si, err := getSessionInfo(ts, sessionId)
+	if err != nil {
+		fatal(err)
+	}
+	if asJson {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "    ")
+		dieIfError(enc.Encode(si))
+		return
+	}
+
+	fmt.Printf("name:      %s\n", si.Name)
+	fmt.Printf("path:      %s\n", si.Path)
+	fmt.Printf("live:      %t\n", si.Live)
+	if si.Live {
+		fmt.Printf("attached:  %t\n", si.Attached)
+		fmt.Printf("windows:   %d\n", si.Windows)
+	}
+	if si.LastActivity != nil {
+		fmt.Printf("activity:  %s\n", si.LastActivity.Format(time.RFC3339))
+	}
+	fmt.Printf("saved:     %t\n", si.Saved)
+	if si.Cmd != "" {
+		fmt.Printf("cmd:       %s\n", si.Cmd)
+	}
+	if len(si.Aliases) > 0 {
+		fmt.Printf("aliases:   %s\n", strings.Join(si.Aliases, ", "))
+	}
+	keys := make([]string, 0, len(si.Env))
+	for k := range si.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("env:       %s=%s\n", k, si.Env[k])
+	}
+}
+
+// sessionCmd это команда и раскладка сессии из конфига для вывода -show-cmd -json
+type sessionCmd struct {
+	Name          string         `json:"name"`
+	Cmd           string         `json:"cmd"`
+	Layout        []LayoutWindow `json:"layout,omitempty"`
+	DefaultWindow int            `json:"default_window,omitempty"`
+}
+
+// printSessionCmd печатает команду, с которой стартует сессия из конфига, и краткое описание
+// её раскладки. Если ни команды, ни раскладки нет, ничего не печатает.
+func printSessionCmd(id string, asJson bool) {
+	fs := Config.FindByNameOrAlias(id)
+	if fs == nil {
+		fatalf("session %s not found in config", id)
+	}
+	if asJson {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "    ")
+		dieIfError(enc.Encode(sessionCmd{fs.Name, fs.Cmd, fs.Layout, fs.DefaultWindow}))
+		return
+	}
+
+	if fs.Cmd != "" {
+		fmt.Printf("%s\n", fs.Cmd)
+	}
+	for i, lw := range fs.Layout {
+		marker := " "
+		if i == fs.DefaultWindow {
+			marker = "*"
+		}
+		fmt.Printf("%s %d %s", marker, i, lw.Window)
+		if lw.Dir != "" {
+			fmt.Printf(" (%s)", lw.Dir)
+		}
+		if lw.Cmd != "" {
+			fmt.Printf(": %s", lw.Cmd)
+		}
+		fmt.Printf("\n")
+	}
+}
+
+// printStatus печатает сводку о текущей сессии: имя, каталог, число окон и наличие .todo.
+// Вне tmux завершается с ошибкой.
+func printStatus(ts *tmuxState) {
+	if os.Getenv("TMUX") == "" {
+		fatalf("not inside tmux")
+	}
+	name := getCurrentSessionName()
+	path := getSessionPath()
+	fmt.Printf("session:   %s\n", name)
+	fmt.Printf("path:      %s\n", path)
+	if s, ok := ts.byName[name]; ok {
+		fmt.Printf("windows:   %d\n", s.WindowsCount)
+	}
+	fname := getTodoFilename(path)
+	if !isFile(fname) {
+		fmt.Printf("todo:      none\n")
+		return
+	}
+	todo := getTodoContents(path)
+	lines := strings.Count(todo, "\n")
+	if todo != "" && !strings.HasSuffix(todo, "\n") {
+		lines++
+	}
+	fmt.Printf("todo:      %s (%d lines)\n", fname, lines)
+}
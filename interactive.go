@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"golang.org/x/sys/unix"
+)
+
+// interactiveHints возвращает строку-подсказку о доступных в -interactive действиях
+func interactiveHints(sessions []TmuxSession, paged bool) string {
+	hints := []string{"name or prefix: switch"}
+	if paged {
+		hints = append(hints, "> <: next/prev page")
+	}
+	hints = append(hints, "=text: filter", "?name: preview")
+	if len(sessions) >= 2 {
+		hints = append(hints, "-: previous")
+	}
+	hints = append(hints, "-T: temp project")
+	hints = append(hints, "new name or path: create")
+	hints = append(hints, "Enter: cancel")
+	return color.New(color.Faint).Sprint(strings.Join(hints, " | "))
+}
+
+// chooseInteractively печатает список сессий и спрашивает у пользователя, на какую переключиться.
+// Возвращает идентификатор сессии или пустую строку, если пользователь ничего не ввёл.
+// Если список не помещается в терминал, он выводится постранично: > и < листают страницы.
+func chooseInteractively(ts *tmuxState) string {
+	sessions := ts.sessions
+	prompt := "input project name to switch to: "
+	if *fPrompt != "" {
+		prompt = *fPrompt
+	} else if Config.InteractivePrompt != "" {
+		prompt = Config.InteractivePrompt
+	}
+
+	allSessions := listedSessions(sessions)
+	state := interactiveState{}
+	if Config.InteractivePersist {
+		state = loadInteractiveState()
+		defer func() { saveInteractiveState(state) }()
+	}
+	previews := previewCache{}
+	preview := ""
+	line := ""
+	for {
+		shown := allSessions
+		if state.Filter != "" {
+			shown = filterSessions(allSessions, state.Filter)
+		}
+		// размер страницы пересчитываем каждый раз: терминал могли растянуть или сжать
+		pageSize := interactivePageSize()
+		paged := *fFormat == "" && pageSize > 0 && len(shown) > pageSize
+		if paged {
+			pages := (len(shown) + pageSize - 1) / pageSize
+			if state.Page >= pages {
+				state.Page = pages - 1
+			}
+			if state.Page < 0 {
+				state.Page = 0
+			}
+			start := state.Page * pageSize
+			end := start + pageSize
+			if end > len(shown) {
+				end = len(shown)
+			}
+			printSessionTable(shown[start:end], *fWide)
+			fmt.Println(color.New(color.Faint).Sprintf("%d-%d/%d", start+1, end, len(shown)))
+		} else if state.Filter != "" {
+			printSessionTable(shown, *fWide)
+		} else {
+			printSessions(ts, *fWide)
+		}
+		if state.Filter != "" {
+			fmt.Println(color.New(color.Faint).Sprintf("filter: %s", state.Filter))
+		}
+		if preview != "" {
+			fmt.Println(preview)
+			preview = ""
+		}
+		if !*fQuiet {
+			fmt.Println(interactiveHints(sessions, paged))
+		}
+		fmt.Print(prompt)
+		line = readLine()
+		if line == ">" {
+			state.Page++
+			continue
+		}
+		if line == "<" {
+			state.Page--
+			continue
+		}
+		if strings.HasPrefix(line, "?") {
+			id := strings.TrimSpace(line[1:])
+			if s, ok := findLiveSession(shown, id); ok && id != "" {
+				preview = previews.preview(s)
+			} else {
+				preview = fmt.Sprintf("nothing matches %s", id)
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "=") {
+			state.Filter = strings.TrimSpace(line[1:])
+			state.Page = 0
+			continue
+		}
+		break
+	}
+	if line == "" {
+		return ""
+	}
+	if line == "-T" {
+		return createTemporaryProject("")
+	}
+	if _, err := resolveTarget(ts, line, *fAllowCreateDir); err != nil {
+		// точного совпадения или совпадения по префиксу нет: попробуем нечёткий поиск,
+		// среди одинаково подходящих сессий выбираем более свежую
+		candidates := withRecentDirs(withSavedSessions(sortByActivity(sessions)))
+		if matches := rankFuzzy(line, candidates, Config.RecencyWeight()); len(matches) > 0 {
+			return matches[0].Session.Name
+		}
+		if !createFromQuery(ts, line) {
+			return ""
+		}
+	}
+	return line
+}
+
+// createFromQuery предлагает создать новый проект из запроса, которому ничего не подошло:
+// путь создаётся как с флагом -c, простое имя - в scratch_root. Возвращает true, если
+// пользователь согласился; тогда ChangeSession создаст проект.
+func createFromQuery(ts *tmuxState, query string) bool {
+	if target, err := resolveTarget(ts, query, true); err == nil && target.CreateDir {
+		if !confirm(fmt.Sprintf("create new project %s?", target.Path)) {
+			return false
+		}
+		*fAllowCreateDir = true
+		return true
+	}
+	if isPlainName(query) {
+		target := scratchTarget(query)
+		if !confirm(fmt.Sprintf("create new project %s?", target.Path)) {
+			return false
+		}
+		*fCreateScratch = true
+		return true
+	}
+	return true
+}
+
+// interactivePageSize возвращает число строк списка, помещающихся в терминал
+// (за вычетом заголовка таблицы, номера страницы, подсказки и приглашения).
+// 0 означает, что размер терминала неизвестен и листать не нужно.
+func interactivePageSize() int {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Row == 0 {
+		return 0
+	}
+	if ws.Row <= 5 {
+		return 1
+	}
+	return int(ws.Row) - 4
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// interactiveStateTTL это время, в течение которого сохранённое состояние -interactive
+// считается актуальным; более старое состояние игнорируется
+const interactiveStateTTL = 5 * time.Minute
+
+// interactiveState это фильтр и страница списка в -interactive, сохраняемые между запусками
+// (если включён interactive_persist)
+type interactiveState struct {
+	Filter  string    `json:"filter,omitempty"`
+	Page    int       `json:"page,omitempty"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// interactiveStateFilename возвращает файл с состоянием -interactive
+func interactiveStateFilename() string {
+	return filepath.Join(Home, ".cache", "pr", "interactive.json")
+}
+
+// loadInteractiveState читает сохранённое состояние -interactive; устаревшее или
+// нечитаемое состояние заменяется пустым
+func loadInteractiveState() interactiveState {
+	state := interactiveState{}
+	bs, err := os.ReadFile(interactiveStateFilename())
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal(bs, &state); err != nil || time.Since(state.SavedAt) > interactiveStateTTL {
+		return interactiveState{}
+	}
+	return state
+}
+
+// saveInteractiveState сохраняет состояние -interactive для следующего запуска
+func saveInteractiveState(state interactiveState) {
+	state.SavedAt = time.Now()
+	bs, err := json.Marshal(state)
+	dieIfError(err)
+	filename := interactiveStateFilename()
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		warn("cannot save interactive state: %s", err)
+		return
+	}
+	if err := os.WriteFile(filename, bs, 0644); err != nil {
+		warn("cannot save interactive state: %s", err)
+	}
+}
+
+// filterSessions возвращает сессии, имя которых нечётко подходит под filter
+// или путь которых содержит filter
+func filterSessions(sessions []TmuxSession, filter string) []TmuxSession {
+	result := []TmuxSession{}
+	for _, s := range sessions {
+		if fuzzyScore(filter, s.Name) > 0 || strings.Contains(strings.ToLower(s.Path), strings.ToLower(filter)) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+)
+
+// resolveLiveSession находит живую сессию по идентификатору так же, как при переключении
+// (resolveTarget): по имени, префиксу, алиасу, без учёта регистра или нечётко; если найденная
+// сессия не запущена под этим именем, ищется живая сессия в её каталоге.
+// Неоднозначный идентификатор - ошибка: угадывать, какую сессию завершить или оставить, нельзя.
+func resolveLiveSession(ts *tmuxState, id string) (TmuxSession, error) {
+	target, err := resolveTarget(ts, id, false)
+	if err != nil {
+		return TmuxSession{}, err
+	}
+	if s, ok := ts.byName[target.Name]; ok {
+		return s, nil
+	}
+	for _, s := range ts.sessions {
+		if s.Path == target.Path {
+			return s, nil
+		}
+	}
+	return TmuxSession{}, fmt.Errorf("no live session matches %s", id)
+}
+
+// killSessionById завершает сессию, найденную по идентификатору, и удаляет из конфига
+// записи временных проектов (из tmp_root), относящиеся к ней
+func killSessionById(ts *tmuxState, id string) {
+	s, err := resolveLiveSession(ts, id)
+	if err != nil {
+		fatal(err)
+	}
+	if err := killSession(s.Name); err != nil {
+		fatal(err)
+	}
+	info("killed %s", s.Name)
+
+	kept := Config.Sessions[:0]
+	for _, fs := range Config.Sessions {
+		path := fs.ExpandedPath()
+		if isTemporaryPath(path) && (fs.Name == s.Name || normalizePath(path) == normalizePath(s.Path)) {
+			Config.changed = true
+			continue
+		}
+		kept = append(kept, fs)
+	}
+	Config.Sessions = kept
+}
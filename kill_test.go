@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestResolveLiveSession(t *testing.T) {
+	withHome(t, t.TempDir())
+	withConfig(t, FavouritesConfig{Sessions: []FavouriteSession{
+		{Name: "backend", Path: "/src/api", Aliases: []string{"be"}},
+		{Name: "shop", Path: "/src/shop", Aliases: []string{"s"}},
+	}})
+	ts := tmuxStateOf([]TmuxSession{
+		{Name: "api", Path: "/src/api"},
+		{Name: "shop", Path: "/src/shop"},
+		{Name: "foobar", Path: "/src/foobar"},
+		{Name: "foobaz", Path: "/src/foobaz"},
+	})
+
+	cases := map[string]string{
+		"shop":   "shop",
+		"sh":     "shop",   // префикс
+		"SHOP":   "shop",   // без учёта регистра
+		"s":      "shop",   // алиас
+		"be":     "api",    // алиас сессии из конфига, живая сессия в её каталоге называется иначе
+		"foobaz": "foobaz", // точное имя важнее префикса
+	}
+	for id, want := range cases {
+		s, err := resolveLiveSession(ts, id)
+		if err != nil || s.Name != want {
+			t.Errorf("resolveLiveSession(%q) = %q, %v, want %q", id, s.Name, err, want)
+		}
+	}
+
+	if s, err := resolveLiveSession(ts, "foo"); err == nil {
+		t.Errorf("resolveLiveSession(foo) = %q, want ambiguity error", s.Name)
+	}
+}
+
+func TestResolveLiveSessionNotRunning(t *testing.T) {
+	withHome(t, t.TempDir())
+	withConfig(t, FavouritesConfig{Sessions: []FavouriteSession{{Name: "web", Path: "/src/web"}}})
+	ts := tmuxStateOf([]TmuxSession{{Name: "api", Path: "/src/api"}})
+	if s, err := resolveLiveSession(ts, "web"); err == nil {
+		t.Errorf("resolveLiveSession(web) = %q, want error: web is not running", s.Name)
+	}
+}
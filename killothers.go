@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// killOthers завершает все живые сессии, кроме перечисленных через запятую в keepList.
+// Текущая сессия, если её нужно завершить, завершается последней.
+func killOthers(ts *tmuxState, keepList string) {
+	sessions := ts.sessions
+	keep := make(map[string]bool)
+	for _, id := range strings.Split(keepList, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		// все идентификаторы проверяются до того, как что-либо будет завершено
+		s, err := resolveLiveSession(ts, id)
+		if err != nil {
+			fatal(err)
+		}
+		keep[s.Name] = true
+	}
+
+	current := ""
+	if os.Getenv("TMUX") != "" {
+		current = getCurrentSessionName()
+	}
+	toKill := []TmuxSession{}
+	for _, s := range sessions {
+		if keep[s.Name] {
+			fmt.Printf("keep %s\n", s.Name)
+			continue
+		}
+		if s.Name == current {
+			continue
+		}
+		toKill = append(toKill, s)
+	}
+	for _, s := range sessions {
+		if s.Name == current && !keep[s.Name] {
+			// текущую сессию завершаем последней, иначе pr завершится вместе с ней
+			toKill = append(toKill, s)
+		}
+	}
+
+	if len(toKill) == 0 {
+		fmt.Printf("nothing to kill\n")
+		return
+	}
+	for _, s := range toKill {
+		fmt.Printf("kill %s\n", s.Name)
+	}
+	if !confirm(fmt.Sprintf("kill %d session(s)?", len(toKill))) {
+		return
+	}
+	killed := 0
+	for _, s := range toKill {
+		if err := killSession(s.Name); err != nil {
+			log.Printf("%s", err)
+			continue
+		}
+		killed++
+	}
+	fmt.Printf("killed %d, kept %d\n", killed, len(sessions)-len(toKill))
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LayoutWindow это окно, создаваемое при старте сессии.
+// Первый элемент раскладки описывает первое окно сессии: оно всегда открывается в каталоге сессии.
+type LayoutWindow struct {
+	Window string `json:"window" yaml:"window"`               // имя окна
+	Dir    string `json:"dir,omitempty" yaml:"dir,omitempty"` // рабочий каталог окна (относительный путь считается от каталога сессии)
+	Cmd    string `json:"cmd,omitempty" yaml:"cmd,omitempty"` // команда, запускаемая в окне
+}
+
+// windowDir возвращает рабочий каталог окна раскладки
+func (lw *LayoutWindow) windowDir(sessionPath string) string {
+	if lw.Dir == "" {
+		return sessionPath
+	}
+	if filepath.IsAbs(lw.Dir) {
+		return lw.Dir
+	}
+	return filepath.Join(sessionPath, lw.Dir)
+}
+
+// cachedBaseIndex это значение опции tmux base-index (-1, пока не запрошено)
+var cachedBaseIndex = -1
+
+// tmuxBaseIndex возвращает номер первого окна в сессиях tmux (опция base-index, обычно 0 или 1)
+func tmuxBaseIndex() int {
+	if cachedBaseIndex < 0 {
+		cachedBaseIndex = 0
+		out, err := exec.Command("tmux", "show-option", "-gv", "base-index").Output()
+		if err == nil {
+			if n, err := strconv.Atoi(strings.TrimSpace(string(out))); err == nil {
+				cachedBaseIndex = n
+			}
+		}
+	}
+	return cachedBaseIndex
+}
+
+// windowTarget возвращает цель tmux для i-го (считая с 0) окна сессии с учётом base-index
+func windowTarget(session string, baseIndex int, i int) string {
+	return fmt.Sprintf("%s:%d", session, baseIndex+i)
+}
+
+// layoutCommand это команда tmux, выполняемая при применении раскладки
+type layoutCommand struct {
+	args []string
+	what string // что делает команда, для сообщения об ошибке
+}
+
+// layoutCommands строит команды tmux, которые называют первое окно только что созданной сессии,
+// создают остальные окна раскладки и выбирают окно defaultWindow (номер в раскладке, считая с 0).
+// baseIndex это опция tmux base-index.
+func layoutCommands(session string, sessionPath string, layout []LayoutWindow, defaultWindow int, baseIndex int) []layoutCommand {
+	cmds := []layoutCommand{}
+	for i, lw := range layout {
+		if i == 0 {
+			if lw.Window != "" {
+				cmds = append(cmds, layoutCommand{
+					args: []string{"rename-window", "-t", windowTarget(session, baseIndex, 0), lw.Window},
+					what: fmt.Sprintf("rename window to %q", lw.Window),
+				})
+			}
+			continue
+		}
+		args := []string{"new-window", "-d", "-t", windowTarget(session, baseIndex, i), "-c", lw.windowDir(sessionPath)}
+		if lw.Window != "" {
+			args = append(args, "-n", lw.Window)
+		}
+		if lw.Cmd != "" {
+			args = append(args, lw.Cmd)
+		}
+		cmds = append(cmds, layoutCommand{args: args, what: fmt.Sprintf("create window %q", lw.Window)})
+	}
+	if defaultWindow > 0 && defaultWindow < len(layout) {
+		cmds = append(cmds, layoutCommand{
+			args: []string{"select-window", "-t", windowTarget(session, baseIndex, defaultWindow)},
+			what: fmt.Sprintf("select window %d", defaultWindow),
+		})
+	}
+	return cmds
+}
+
+// applyLayout применяет раскладку к только что созданной сессии (см. layoutCommands).
+// Ошибки не фатальны: сессия уже создана, поэтому просто сообщаем о них.
+func applyLayout(session string, sessionPath string, layout []LayoutWindow, defaultWindow int) {
+	if len(layout) == 0 {
+		// без раскладки не нужно и спрашивать у tmux base-index
+		return
+	}
+	for _, c := range layoutCommands(session, sessionPath, layout, defaultWindow, tmuxBaseIndex()) {
+		out, err := exec.Command("tmux", c.args...).CombinedOutput()
+		logCommand(session, c.args, err, out)
+		if err != nil {
+			log.Printf("cannot %s: %s: %s", c.what, err, strings.TrimSpace(string(out)))
+		}
+	}
+}
+
+// validateLayout проверяет раскладку сессии из конфига и возвращает список проблем:
+// несуществующие каталоги окон, повторяющиеся имена окон, команды из одних пробелов,
+// номер окна по умолчанию вне раскладки. Пустая команда проблемой не считается:
+// окно без cmd открывается с обычным shell.
+func validateLayout(fs *FavouriteSession) []string {
+	problems := []string{}
+	if !isDir(fs.ExpandedPath()) {
+		problems = append(problems, fmt.Sprintf("session directory %s does not exist", fs.ExpandedPath()))
+	}
+	seen := make(map[string]int)
+	for i, lw := range fs.Layout {
+		if lw.Window != "" {
+			if j, ok := seen[lw.Window]; ok {
+				problems = append(problems, fmt.Sprintf("window %d: name %q is already used by window %d", i, lw.Window, j))
+			} else {
+				seen[lw.Window] = i
+			}
+		}
+		if lw.Dir != "" {
+			if i == 0 {
+				problems = append(problems, fmt.Sprintf("window %d: dir is ignored, the first window opens in the session directory", i))
+			} else if dir := lw.windowDir(fs.ExpandedPath()); !isDir(dir) {
+				problems = append(problems, fmt.Sprintf("window %d: directory %s does not exist", i, dir))
+			}
+		}
+		// cmd из одних пробелов это скорее опечатка в конфиге, чем намеренный shell
+		if lw.Cmd != "" && strings.TrimSpace(lw.Cmd) == "" {
+			problems = append(problems, fmt.Sprintf("window %d: cmd is blank", i))
+		}
+	}
+	if fs.DefaultWindow < 0 || (fs.DefaultWindow > 0 && fs.DefaultWindow >= len(fs.Layout)) {
+		problems = append(problems, fmt.Sprintf("default_window %d is out of layout (%d windows)", fs.DefaultWindow, len(fs.Layout)))
+	}
+	return problems
+}
+
+// printLayoutProblems печатает проблемы раскладки сессии из конфига, ничего не создавая.
+// Если проблемы есть, pr завершается с ненулевым кодом.
+func printLayoutProblems(id string) {
+	fs := Config.FindByNameOrAlias(id)
+	if fs == nil {
+		fatalf("session %s not found in config", id)
+	}
+	problems := validateLayout(fs)
+	if len(problems) == 0 {
+		info("layout of %s is ok (%d windows)", fs.Name, len(fs.Layout))
+		return
+	}
+	for _, p := range problems {
+		fmt.Printf("%s: %s\n", fs.Name, p)
+	}
+	fatalf("layout of %s has %d problem(s)", fs.Name, len(problems))
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateLayoutValid(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "log"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	fs := &FavouriteSession{
+		Name: "api",
+		Path: dir,
+		Layout: []LayoutWindow{
+			{Window: "edit", Cmd: "vim"},
+			{Window: "shell"},
+			{Window: "logs", Dir: "log", Cmd: "tail -f app.log"},
+			{Dir: dir},
+		},
+		DefaultWindow: 2,
+	}
+	if problems := validateLayout(fs); len(problems) != 0 {
+		t.Errorf("validateLayout() = %q, want no problems", problems)
+	}
+}
+
+func TestValidateLayoutInvalid(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		name    string
+		fs      FavouriteSession
+		problem string
+	}{
+		{
+			name:    "missing session dir",
+			fs:      FavouriteSession{Path: filepath.Join(dir, "missing")},
+			problem: "session directory",
+		},
+		{
+			name:    "duplicate window name",
+			fs:      FavouriteSession{Path: dir, Layout: []LayoutWindow{{Window: "a"}, {Window: "a"}}},
+			problem: `name "a" is already used by window 0`,
+		},
+		{
+			name:    "dir of first window",
+			fs:      FavouriteSession{Path: dir, Layout: []LayoutWindow{{Dir: "sub"}}},
+			problem: "dir is ignored",
+		},
+		{
+			name:    "missing window dir",
+			fs:      FavouriteSession{Path: dir, Layout: []LayoutWindow{{}, {Dir: "missing"}}},
+			problem: "window 1: directory",
+		},
+		{
+			name:    "blank cmd",
+			fs:      FavouriteSession{Path: dir, Layout: []LayoutWindow{{}, {Cmd: "  "}}},
+			problem: "window 1: cmd is blank",
+		},
+		{
+			name:    "default window out of layout",
+			fs:      FavouriteSession{Path: dir, Layout: []LayoutWindow{{}, {}}, DefaultWindow: 2},
+			problem: "default_window 2 is out of layout",
+		},
+		{
+			name:    "negative default window",
+			fs:      FavouriteSession{Path: dir, DefaultWindow: -1},
+			problem: "default_window -1",
+		},
+	}
+	for _, c := range cases {
+		problems := validateLayout(&c.fs)
+		if len(problems) != 1 || !strings.Contains(problems[0], c.problem) {
+			t.Errorf("%s: validateLayout() = %q, want one problem containing %q", c.name, problems, c.problem)
+		}
+	}
+}
+
+func TestLayoutCommands(t *testing.T) {
+	layout := []LayoutWindow{
+		{Window: "edit", Cmd: "vim"},
+		{Window: "logs", Dir: "log", Cmd: "tail -f app.log"},
+		{Dir: "/abs"},
+	}
+	want := []layoutCommand{
+		{args: []string{"rename-window", "-t", "api:1", "edit"}, what: `rename window to "edit"`},
+		{args: []string{"new-window", "-d", "-t", "api:2", "-c", "/src/api/log", "-n", "logs", "tail -f app.log"}, what: `create window "logs"`},
+		{args: []string{"new-window", "-d", "-t", "api:3", "-c", "/abs"}, what: `create window ""`},
+		{args: []string{"select-window", "-t", "api:2"}, what: "select window 1"},
+	}
+	got := layoutCommands("api", "/src/api", layout, 1, 1)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("layoutCommands() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLayoutCommandsUnnamedFirstWindow(t *testing.T) {
+	// первое окно без имени не переименовывается, окно по умолчанию 0 не выбирается явно
+	layout := []LayoutWindow{{}, {Window: "shell"}}
+	want := []layoutCommand{
+		{args: []string{"new-window", "-d", "-t", "api:1", "-c", "/src/api", "-n", "shell"}, what: `create window "shell"`},
+	}
+	got := layoutCommands("api", "/src/api", layout, 0, 0)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("layoutCommands() = %+v, want %+v", got, want)
+	}
+	if got := layoutCommands("api", "/src/api", nil, 3, 0); len(got) != 0 {
+		t.Errorf("layoutCommands(no layout) = %+v, want no commands", got)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sortByActivity возвращает копию списка сессий, отсортированную по последней активности (сначала свежие)
+func sortByActivity(sessions []TmuxSession) []TmuxSession {
+	sortedSessions := make([]TmuxSession, len(sessions))
+	copy(sortedSessions, sessions)
+	sort.SliceStable(sortedSessions, func(i, j int) bool {
+		return sortedSessions[i].LastActivity.After(sortedSessions[j].LastActivity)
+	})
+	return sortedSessions
+}
+
+// sortSessions возвращает копию списка сессий, отсортированную по ключу -sort:
+// name (по имени), activity (сначала свежие), windows (сначала с большим числом окон),
+// attached (сначала подключённые). Пустой ключ оставляет порядок без изменений.
+func sortSessions(sessions []TmuxSession, key string) ([]TmuxSession, error) {
+	var less func(a, b TmuxSession) bool
+	switch key {
+	case "":
+		return sessions, nil
+	case "activity":
+		return sortByActivity(sessions), nil
+	case "name":
+		less = func(a, b TmuxSession) bool { return a.Name < b.Name }
+	case "windows":
+		less = func(a, b TmuxSession) bool { return a.WindowsCount > b.WindowsCount }
+	case "attached":
+		less = func(a, b TmuxSession) bool { return a.Attached && !b.Attached }
+	default:
+		return nil, fmt.Errorf("unknown sort key %s: use name, activity, windows or attached", key)
+	}
+	sortedSessions := make([]TmuxSession, len(sessions))
+	copy(sortedSessions, sessions)
+	sort.SliceStable(sortedSessions, func(i, j int) bool {
+		return less(sortedSessions[i], sortedSessions[j])
+	})
+	return sortedSessions, nil
+}
+
+// pinnedFirst переставляет закреплённые сессии в начало списка в порядке pinned,
+// порядок остальных сессий не меняется
+func pinnedFirst(sessions []TmuxSession, pinned []string) []TmuxSession {
+	if len(pinned) == 0 {
+		return sessions
+	}
+	isPinned := make(map[string]bool)
+	for _, name := range pinned {
+		isPinned[name] = true
+	}
+	result := make([]TmuxSession, 0, len(sessions))
+	for _, name := range pinned {
+		for _, s := range sessions {
+			if s.Name == name {
+				result = append(result, s)
+				break
+			}
+		}
+	}
+	for _, s := range sessions {
+		if !isPinned[s.Name] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// activeSince возвращает сессии, активные после момента since
+func activeSince(sessions []TmuxSession, since time.Time) []TmuxSession {
+	result := []TmuxSession{}
+	for _, s := range sessions {
+		if s.LastActivity.After(since) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// withMinWindows возвращает сессии, в которых не меньше n окон
+func withMinWindows(sessions []TmuxSession, n int) []TmuxSession {
+	result := []TmuxSession{}
+	for _, s := range sessions {
+		if s.WindowsCount >= n {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// withMatching возвращает сессии, имя или каталог которых подходит под регулярное выражение re
+func withMatching(sessions []TmuxSession, re *regexp.Regexp) []TmuxSession {
+	result := []TmuxSession{}
+	for _, s := range sessions {
+		if re.MatchString(s.Name) || re.MatchString(s.Path) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// truncatePath оставляет от пути только n последних элементов ("/home/me/work/api" при n=2
+// превращается в ".../work/api"). n <= 0 и короткие пути возвращаются без изменений.
+func truncatePath(path string, n int) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if n <= 0 || len(parts) <= n {
+		return path
+	}
+	return ".../" + strings.Join(parts[len(parts)-n:], "/")
+}
+
+// parseSince разбирает аргумент -since: время сегодняшнего дня (9am, 9:30pm, 14:30)
+// или длительность (8h, 90m), отсчитываемую назад от now
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{"15:04", "3pm", "3:04pm", "3PM", "3:04PM"} {
+		t, err := time.ParseInLocation(layout, strings.TrimSpace(s), now.Location())
+		if err == nil {
+			return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location()), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse -since %q: expected time of day (9am, 14:30) or duration (8h)", s)
+}
+
+// printPaths выводит уникальные каталоги живых и сохранённых сессий, по одному на строку
+func printPaths(sessions []TmuxSession) {
+	seen := make(map[string]bool)
+	paths := []string{}
+	add := func(p string) {
+		p = normalizePath(p)
+		if p != "" && !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+	for _, s := range sessions {
+		add(s.Path)
+	}
+	for _, fs := range Config.Sessions {
+		add(fs.ExpandedPath())
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		fmt.Println(p)
+	}
+}
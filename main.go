@@ -19,17 +19,44 @@ package main
 //
 //   В качестве аргумента можно указывать:
 //   - абсолютный путь к существующуему каталогу проекта
-//   - абсолютный путь к каталогу внутри /tmp, не обязательно существующему (например /tmp/1)
-//   - имя подкаталога внутри домашней директории пользователя
-//   - префикс имени подкаталога внутри домашней директории пользователя
+//   - абсолютный путь к каталогу внутри /tmp, не обязательно существующему (например /tmp/1),
+//     если в конфиге не выключен auto_create_tmp
+//   - имя подкаталога внутри домашней директории пользователя (или каталогов из roots в конфиге)
+//   - префикс имени подкаталога внутри домашней директории пользователя (или каталогов из roots)
 //   - точку (текущий каталог)
 //   - имя сессии tmux или префикс имени
 //   - имя из сессии, сохранённой в конфиге ~/.config/pr.yaml
 //   - дефис (pr -) переключает на предыдущую сессию
 //
-// * pr -T
+// * pr -prev, pr -next
 //
-//   создаёт временный проект-директорию /tmp/tN (где N это порядковый номер).
+//   шагают назад и вперёд по истории переключений (history в конфиге).
+//
+// * pr -b
+//
+//   возвращается на сессию, с которой последний раз переключились внутри tmux.
+//
+// * pr -T [имя]
+//
+//   создаёт временный проект-директорию /tmp/tN (где N это порядковый номер),
+//   а с именем - /tmp/<имя> (или /tmp/<имя>N, если такой каталог уже есть).
+//   Вместо /tmp можно указать другой каталог параметром tmp_root в конфиге.
+//   pr -T-clean удаляет пустые временные проекты, в которых нет живых сессий.
+//
+// * pr -new <имя> [-at <каталог>] [-cmd <команда>] [-no-switch]
+//
+//   создаёт сессию с указанным именем в каталоге (по умолчанию текущем) и запускает в ней команду.
+//   С -no-switch сессия создаётся в фоне.
+//
+// * pr -in <имя> -- <команда...>
+//
+//   запускает команду (вне tmux) в каталоге проекта, найденного так же, как при переключении.
+//
+// * pr -send <имя> <клавиши...>
+//
+//   передаёт клавиши в сессию (tmux send-keys), например pr -send proj 'git pull' Enter.
+//   В отличие от -cmd, который задаёт команду при создании новой сессии, -send работает
+//   с уже открытой сессией (если её нет, она создаётся в фоне).
 //
 // * pr -edit
 //
@@ -41,14 +68,20 @@ package main
 //
 //   посмотреть содержимое всех .todo можно, выполнив pr -w
 //
+// * pr -batch <файл>
+//
+//   выполняет команды pr из файла (или из stdin, если файл -), по одной на строку,
+//   в одном процессе; конфиг записывается один раз в конце.
+//
 //
 // Добавить переключалку в tmux: допишите в ~/.tmux.conf строку:
 //
-//   bind P display-popup -E -E "pr --interactive"
+//   bind P display-popup -E -E "pr -fzf"
+//
+// (без установленного fzf pr -fzf работает как pr --interactive)
 
 import (
 	"bufio"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
@@ -56,6 +89,7 @@ import (
 	"os/exec"
 	"os/user"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -63,6 +97,8 @@ import (
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"github.com/mattn/go-runewidth"
 	"github.com/rodaine/table"
 )
 
@@ -71,14 +107,96 @@ const (
 )
 
 var (
-	fAllowCreateDir  = flag.Bool("c", false, "create project dir if not exists")
-	fTempProject     = flag.Bool("T", false, "create temporary project /tmp/tN")
-	fWide            = flag.Bool("w", false, "wide output: print all columns")
-	fEditConfig      = flag.Bool("edit", false, "open pr config in text editor")
-	fShowAllSessions = flag.Bool("a", false, "show all sessions (including saved and inactive)")
-	fInteractive     = flag.Bool("interactive", false, "interactive mode for using with tmux: show all sessions then allow user to choose one of them or exit")
-	fTodo            = new(bool)
-	fVersion         = flag.Bool("version", false, "show pr version")
+	fAllowCreateDir       = flag.Bool("c", false, "create project dir if not exists")
+	fTempProject          = flag.Bool("T", false, "create temporary project /tmp/tN, or /tmp/<name> with pr -T <name>")
+	fWide                 = flag.Bool("w", false, "wide output: print all columns")
+	fEditConfig           = flag.Bool("edit", false, "open pr config in text editor")
+	fShowAllSessions      = flag.Bool("a", false, "show all sessions (including saved and inactive)")
+	fInteractive          = flag.Bool("interactive", false, "interactive mode for using with tmux: show all sessions then allow user to choose one of them or exit")
+	fTodo                 = new(bool)
+	fVersion              = flag.Bool("version", false, "show pr version")
+	fRenameCurrentTo      = flag.String("rename-current-to", "", "rename current tmux session (adds a suffix if the name is taken)")
+	fKeepAlias            = flag.Bool("keep-alias", false, "with -rename-current-to: keep the old session name as an alias in the config")
+	fPathsOnly            = flag.Bool("paths-only", false, "print unique paths of live and saved sessions, one per line")
+	fAskName              = flag.Bool("ask-name", false, "ask for the name of a new session before creating it")
+	fAutoClean            = flag.Duration("auto-clean", 0, "kill detached sessions idle longer than the given duration, if their git repo has no uncommitted changes")
+	fYes                  = flag.Bool("y", false, "do not ask for confirmation")
+	fCloneSession         = flag.String("clone-session", "", "create a copy of a live session with the same directory and windows")
+	fPrompt               = flag.String("prompt", "", "prompt text for -interactive mode")
+	fSessionInfo          = flag.String("session-info", "", "print everything known about a session (live state and config)")
+	fJson                 = flag.Bool("json", false, "print output in JSON format")
+	fSince                = flag.String("since", "", "list only sessions active since the given time of day (9am, 14:30) or duration ago (8h)")
+	fPinDisplay           = flag.String("pin-display", "", "pin session to the top of the list (or unpin if already pinned)")
+	fKillWindow           = flag.Bool("kill-window", false, "kill current window or window given as argument (session:window)")
+	fRecordDir            = flag.String("record-dir", "", "record directory in the recent dirs list (for calling from a shell cd hook)")
+	fExportTmuxinator     = flag.String("export-tmuxinator", "", "print tmuxinator project YAML for a saved session")
+	fFzf                  = flag.Bool("fzf", false, "choose session with fzf (falls back to -interactive if fzf is not installed)")
+	fImportTmuxinator     = flag.String("import-tmuxinator", "", "import tmuxinator project file into the config")
+	fNewClient            = flag.Bool("new-client", false, "open session in a new terminal window (see terminal option in the config)")
+	fConfig               = flag.String("config", "", "path to config file, .yaml or .json (default $XDG_CONFIG_HOME/pr.yaml or ~/.config/pr.yaml, or pr.json if it exists)")
+	fNoTouch              = flag.Bool("no-touch", false, "do not move the session to the top of the history when switching")
+	fTmpReport            = flag.Bool("tmp-report", false, "list temporary projects /tmp/tN and their sessions")
+	fRenumber             = flag.Bool("renumber", false, "with -tmp-report: renumber temporary projects to fill the gaps (refused if a project with a live session would have to move)")
+	fWhichEditor          = flag.Bool("which-editor", false, "print the editor used by -edit and -todo")
+	fBack                 = flag.Bool("b", false, "switch back to the session attached before the last switch")
+	fTree                 = flag.Bool("tree", false, "print sessions as a tree grouped by directory")
+	fCreateScratch        = flag.Bool("create-scratch", false, "if nothing matches, create a new project in scratch_root (default ~/scratch)")
+	fAliasSuggest         = flag.Bool("alias-suggest", false, "suggest short aliases for saved sessions with long names")
+	fApply                = flag.Bool("apply", false, "apply changes suggested by -alias-suggest or -dedupe-aliases")
+	fHistoryClear         = flag.Bool("history-clear", false, "remove sessions without cmd, env, aliases or layout from the config history")
+	fNew                  = flag.String("new", "", "create a new session with the given name")
+	fAt                   = flag.String("at", "", "with -new: session directory (default: current directory)")
+	fCmd                  = flag.String("cmd", "", "with -new: command to run in the new session")
+	fNoSwitch             = flag.Bool("no-switch", false, "create session but do not switch to it")
+	fQuiet                = flag.Bool("quiet", false, "do not print informational messages and warnings (errors are still printed)")
+	fIn                   = flag.String("in", "", "run a command in the project directory: pr -in <name> -- <cmd...>")
+	fFormat               = flag.String("format", "", "print each listed session using a Go template, e.g. '{{.Name}}\\t{{.Path}}' (fields: Name, Path, Windows, Attached, LastActivity, Live)")
+	fRenameRegex          = flag.String("rename-regex", "", "rename sessions matching the regex: pr -rename-regex <regex> <replacement> (replacement may use $1)")
+	fSyncActivity         = flag.Bool("sync-activity", false, "update last used time of saved sessions from the activity of live sessions")
+	fKillOthers           = flag.String("kill-others", "", "kill all live sessions except the given comma-separated ones")
+	fSetRoot              = flag.String("set-root", "", "change the directory of a saved session: pr -set-root <session> <path>")
+	fValidateLayout       = flag.String("validate-layout", "", "check the layout of a saved session without creating it")
+	fComplete             = flag.Bool("complete", false, "print session names and aliases for shell completion")
+	fCompleteDescriptions = flag.Bool("complete-descriptions", false, "with -complete: print name:description pairs (for zsh)")
+	fMove                 = flag.String("move", "", "move project directory and update saved sessions: pr -move <session> <new path>")
+	fGitMv                = flag.Bool("git-mv", false, "with -move: use git mv if the directory is tracked by git")
+	fShowCmd              = flag.String("show-cmd", "", "print the start command and layout of a saved session")
+	fDedupeAliases        = flag.Bool("dedupe-aliases", false, "report aliases that duplicate other aliases or shadow session names (remove them with -apply)")
+	fKill                 = flag.String("k", "", "kill session by name, prefix or alias")
+	fRename               = flag.String("rename", "", "rename a live session and its saved entry: pr -rename <session> <new name>")
+	fPurgeTmpSessions     = flag.Bool("purge-tmp-sessions", false, "kill all sessions in temporary projects and remove their directories")
+	fForce                = flag.Bool("force", false, "with -purge-tmp-sessions: remove non-empty directories too")
+	fNoSavedDups          = flag.Bool("no-saved-dups", false, "with -a: hide saved sessions whose directory is already open in a live session")
+	fCapture              = flag.String("capture", "", "print the contents of the current pane of a session")
+	fOutput               = flag.String("o", "", "with -capture: save output to the file instead of printing it")
+	fAllWindows           = flag.Bool("all-windows", false, "with -capture: capture active panes of all windows")
+	fSend                 = flag.String("send", "", "send keys to a session (tmux send-keys): pr -send <session> <keys...>")
+	fTodoAll              = flag.Bool("todo-all", false, "print TODO files of all live and saved sessions")
+	fPruneEphemeral       = flag.Bool("prune-ephemeral", false, "remove saved sessions in tmp_root or ephemeral_prefixes from the config")
+	fTodoAdd              = flag.String("todo-add", "", "append a line to the TODO file of the current project")
+	fMinWindows           = flag.Int("min-windows", 0, "list only sessions with at least the given number of windows")
+	fSort                 = flag.String("sort", "", "sort the listing by name, activity, windows or attached (default: tmux order, saved sessions last)")
+	fFromGitBranch        = flag.Bool("from-git-branch", false, "rename current session (or window with -window) to <dir>/<git branch>")
+	fWindow               = flag.Bool("window", false, "open the project in a new window of the current session instead of switching to it; with -from-git-branch: rename current window instead of the session")
+	fPrune                = flag.Bool("prune", false, "remove saved sessions whose directories no longer exist")
+	fDryRun               = flag.Bool("dry-run", false, "do not save config changes (with -prune: only print what would be removed)")
+	fConfigDiff           = flag.Bool("config-diff", false, "print changes to the config before saving it")
+	fRelativeTime         = flag.Bool("rel", false, "show activity time relative to now (5m, 3h, 2d)")
+	fFirstRun             = flag.Bool("first-run", false, "set up pr interactively (runs automatically when there is no config)")
+	fCompletion           = flag.String("completion", "", "print shell completion script: bash or zsh")
+	fJsonLines            = flag.Bool("jsonl", false, "print session list as JSON lines, one compact object per session")
+	fBatch                = flag.String("batch", "", "run pr commands from a file (- for stdin), one per line, saving the config once at the end")
+	fTempClean            = flag.Bool("T-clean", false, "remove empty temporary projects /tmp/tN that have no live session")
+	fPrev                 = flag.Bool("prev", false, "switch to the previous session in switch history")
+	fNext                 = flag.Bool("next", false, "switch to the next session in switch history (after -prev)")
+	fAlias                = flag.String("alias", "", "manage aliases of a saved session: pr -alias add|remove <session> <alias>")
+	fEnv                  = flag.String("env", "", "manage environment of a saved session: pr -env set <session> KEY=VALUE or pr -env unset <session> KEY")
+	fStatus               = flag.Bool("status", false, "print a summary of the current session: name, path, windows and .todo")
+	fGrep                 = flag.String("grep", "", "list only sessions whose name or path matches the regular expression")
+	fWindowNames          = flag.Bool("windows", false, "add a column with window names of each session (runs tmux once per session)")
+	fGitColumn            = flag.Bool("git", false, "add a column with the current git branch of each session (runs git once per session)")
+	fDetach               = flag.String("detach", "", "detach all clients from a session (by name, prefix or alias)")
+	fTruncate             = flag.Int("truncate", 0, "show only the last N components of session paths in the table (0: full paths)")
 )
 
 func init() {
@@ -95,8 +213,15 @@ var (
 func init() {
 	u, _ := user.Current()
 	Home = u.HomeDir
-	ConfigPath = filepath.Join(Home, ".config", "pr.json")
-	Config.Load()
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		configDir = filepath.Join(Home, ".config")
+	}
+	// основной формат конфига YAML, но старый pr.json продолжает работать, если pr.yaml нет
+	ConfigPath = filepath.Join(configDir, "pr.yaml")
+	if legacy := filepath.Join(configDir, "pr.json"); !isFile(ConfigPath) && isFile(legacy) {
+		ConfigPath = legacy
+	}
 }
 
 func dieIfError(err error) {
@@ -105,13 +230,62 @@ func dieIfError(err error) {
 	}
 }
 
+// inBatch равен true, пока выполняются команды -batch
+var inBatch bool
+
+// batchLineError это ошибка, прервавшая одну строку -batch (см. fatalf)
+type batchLineError struct {
+	msg string
+}
+
+func (e batchLineError) Error() string {
+	return e.msg
+}
+
+// fatalf печатает ошибку пользователя и завершает pr с кодом 1. В -batch вместо этого
+// прерывается только текущая строка: runBatch сообщит об ошибке и продолжит со следующей.
+func fatalf(format string, v ...interface{}) {
+	if inBatch {
+		panic(batchLineError{fmt.Sprintf(format, v...)})
+	}
+	log.Fatalf(format, v...)
+}
+
+// fatal это fatalf с аргументами как у log.Fatal
+func fatal(v ...interface{}) {
+	fatalf("%s", fmt.Sprint(v...))
+}
+
+// info печатает информационное сообщение, если не задан флаг -quiet
+func info(format string, v ...interface{}) {
+	if !*fQuiet {
+		fmt.Printf(format+"\n", v...)
+	}
+}
+
+// warn печатает предупреждение в stderr, если не задан флаг -quiet
+func warn(format string, v ...interface{}) {
+	if !*fQuiet {
+		log.Printf(format, v...)
+	}
+}
+
 // FavouriteSession это сессия, запомненная в истории / конфиге
 type FavouriteSession struct {
-	Name    string            `json:"name"`
-	Path    string            `json:"path"`
-	Cmd     string            `json:"cmd"` // команда, выполняющаяся при старте сессии
-	Aliases []string          `json:"aliases"`
-	Env     map[string]string `json:"env"` // переменные окружения, с которыми стартует сессия
+	Name               string            `json:"name" yaml:"name"`
+	Path               string            `json:"path" yaml:"path"`
+	Cmd                string            `json:"cmd" yaml:"cmd"` // команда, выполняющаяся при старте сессии
+	Aliases            []string          `json:"aliases" yaml:"aliases"`
+	Env                map[string]string `json:"env" yaml:"env"`                                                         // переменные окружения, с которыми стартует сессия
+	Layout             []LayoutWindow    `json:"layout,omitempty" yaml:"layout,omitempty"`                               // окна, создаваемые при старте сессии
+	DefaultWindow      int               `json:"default_window,omitempty" yaml:"default_window,omitempty"`               // окно раскладки (номер с 0), выбранное после старта сессии
+	LastUsed           *time.Time        `json:"last_used,omitempty" yaml:"last_used,omitempty"`                         // время последнего переключения на сессию (или активности, см. -sync-activity)
+	OnSwitchWindowName string            `json:"on_switch_window_name,omitempty" yaml:"on_switch_window_name,omitempty"` // шаблон имени активного окна, задаваемого при переключении на сессию
+}
+
+// ExpandedPath возвращает каталог сессии с раскрытыми ~ и переменными окружения
+func (f *FavouriteSession) ExpandedPath() string {
+	return expandPath(f.Path)
 }
 
 // TmuxSession это сессия в живом tmux
@@ -119,6 +293,7 @@ type TmuxSession struct {
 	Name         string
 	Attached     bool
 	LastActivity time.Time
+	Created      time.Time
 	WindowsCount int
 	Path         string
 }
@@ -128,28 +303,78 @@ func (ts *TmuxSession) String() string {
 }
 
 func (ts *TmuxSession) FmtLastActivity() string {
-	t := ""
-	if !ts.LastActivity.IsZero() {
-		dt := time.Since(ts.LastActivity)
+	return fmtSessionTime(ts.LastActivity)
+}
+
+func (ts *TmuxSession) FmtCreated() string {
+	return fmtSessionTime(ts.Created)
+}
+
+// fmtSessionTime форматирует время для таблицы: время суток для последних суток, иначе дату.
+// С флагом -rel выводит, сколько времени прошло
+func fmtSessionTime(t time.Time) string {
+	s := ""
+	if !t.IsZero() && *fRelativeTime {
+		s = fmtRelativeTime(time.Since(t))
+	} else if !t.IsZero() {
+		dt := time.Since(t)
 		if dt < 24*time.Hour {
-			t = ts.LastActivity.Format("15:04:05")
+			s = t.Format("15:04:05")
 		} else {
-			t = ts.LastActivity.Format("Jan 02")
+			s = t.Format("Jan 02")
 		}
 	}
-	return t
+	return s
+}
+
+// fmtRelativeTime форматирует прошедшее время: just now, 5m, 3h, 2d
+func fmtRelativeTime(dt time.Duration) string {
+	switch {
+	case dt < time.Minute:
+		return "just now"
+	case dt < time.Hour:
+		return fmt.Sprintf("%dm", int(dt/time.Minute))
+	case dt < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(dt/time.Hour))
+	}
+	return fmt.Sprintf("%dd", int(dt/(24*time.Hour)))
 }
 
 func (ts *TmuxSession) FmtAttached() string {
 	if ts.Attached {
+		if Config.AttachedMarker != "" {
+			return Config.AttachedMarker
+		}
 		return "*"
 	}
 	return ""
 }
 
 type FavouritesConfig struct {
-	Sessions []FavouriteSession `json:"sessions"`
-	changed  bool
+	Sessions           []FavouriteSession     `json:"sessions" yaml:"sessions"`
+	InteractivePrompt  string                 `json:"interactive_prompt,omitempty" yaml:"interactive_prompt,omitempty"`     // приглашение в режиме -interactive
+	FuzzyRecencyWeight *float64               `json:"fuzzy_recency_weight,omitempty" yaml:"fuzzy_recency_weight,omitempty"` // вес недавности при нечётком поиске в -interactive (по умолчанию 1)
+	AutoCreateTmp      *bool                  `json:"auto_create_tmp,omitempty" yaml:"auto_create_tmp,omitempty"`           // создавать каталоги в /tmp без флага -c (по умолчанию да)
+	PinnedSessions     []string               `json:"pinned_sessions,omitempty" yaml:"pinned_sessions,omitempty"`           // сессии, которые всегда показываются первыми в списке
+	RecentDirsFile     string                 `json:"recent_dirs_file,omitempty" yaml:"recent_dirs_file,omitempty"`         // файл с недавними каталогами (см. -record-dir)
+	Terminal           string                 `json:"terminal,omitempty" yaml:"terminal,omitempty"`                         // команда терминала для -new-client, например "alacritty -e"
+	TouchOnSwitch      *bool                  `json:"touch_on_switch,omitempty" yaml:"touch_on_switch,omitempty"`           // перемещать сессию в начало истории при переключении (по умолчанию да)
+	LastAttached       string                 `json:"last_attached,omitempty" yaml:"last_attached,omitempty"`               // сессия, с которой последний раз переключились (для pr -b)
+	ScratchRoot        string                 `json:"scratch_root,omitempty" yaml:"scratch_root,omitempty"`                 // каталог для новых проектов, создаваемых с -create-scratch (по умолчанию ~/scratch)
+	DetectProjectType  bool                   `json:"detect_project_type,omitempty" yaml:"detect_project_type,omitempty"`   // определять тип проекта при создании сессии (см. project_types)
+	ProjectTypes       map[string]ProjectType `json:"project_types,omitempty" yaml:"project_types,omitempty"`               // команда и окружение по умолчанию для типов проектов: go, node, rust
+	AttachedMarker     string                 `json:"attached_marker,omitempty" yaml:"attached_marker,omitempty"`           // отметка подключённой сессии в списке (по умолчанию "*")
+	LogCommands        bool                   `json:"log_commands,omitempty" yaml:"log_commands,omitempty"`                 // записывать команды, выполненные при создании сессий, в <каталог конфига>/logs/<сессия>.log
+	InteractivePersist bool                   `json:"interactive_persist,omitempty" yaml:"interactive_persist,omitempty"`   // запоминать фильтр и страницу -interactive между запусками (на 5 минут)
+	WindowSizeMode     string                 `json:"window_size_mode,omitempty" yaml:"window_size_mode,omitempty"`         // опция tmux window-size, устанавливаемая сессии при переключении: largest, smallest, manual или latest
+	InteractivePreview string                 `json:"interactive_preview,omitempty" yaml:"interactive_preview,omitempty"`   // что показывает ?имя в -interactive: todo, git или both (по умолчанию todo)
+	TmpRoot            string                 `json:"tmp_root,omitempty" yaml:"tmp_root,omitempty"`                         // каталог временных проектов (по умолчанию /tmp): сессии в нём не сохраняются в истории
+	OnServerStart      string                 `json:"on_server_start,omitempty" yaml:"on_server_start,omitempty"`           // команда shell, выполняемая после того, как pr запустил сервер tmux (например, для глобальных опций)
+	EphemeralPrefixes  []string               `json:"ephemeral_prefixes,omitempty" yaml:"ephemeral_prefixes,omitempty"`     // префиксы путей (кроме tmp_root), сессии в которых не сохраняются в истории, например "/var/tmp/"
+	Roots              []string               `json:"roots,omitempty" yaml:"roots,omitempty"`                               // каталоги, в которых ищутся проекты по имени и префиксу (по умолчанию домашняя директория), например ["~/work", "~/src"]
+	History            []string               `json:"history,omitempty" yaml:"history,omitempty"`                           // сессии в порядке переключения на них, самые новые в конце (для -prev и -next)
+	changed            bool
+	deferSave          bool // не записывать конфиг в Save (в -batch конфиг записывается один раз в конце)
 }
 
 func (fc *FavouritesConfig) Load() {
@@ -157,24 +382,120 @@ func (fc *FavouritesConfig) Load() {
 	if err != nil {
 		return
 	}
-	err = json.Unmarshal(bs, fc)
+	err = configFormatFor(ConfigPath).Unmarshal(bs, fc)
 	dieIfError(err)
 	fc.changed = false
 }
 
 func (fc *FavouritesConfig) Save() {
+	if fc.deferSave {
+		return
+	}
 	if !fc.changed {
+		if *fConfigDiff {
+			info("config is unchanged")
+		}
+		return
+	}
+	bs, err := configFormatFor(ConfigPath).Marshal(fc)
+	dieIfError(err)
+	if *fConfigDiff {
+		printConfigDiff(bs)
+	}
+	if *fDryRun {
 		return
 	}
-	bs, err := json.MarshalIndent(fc, "", "    ")
+	err = os.MkdirAll(filepath.Dir(ConfigPath), 0755)
 	dieIfError(err)
 	err = os.WriteFile(ConfigPath, bs, 0640)
 	dieIfError(err)
 }
 
+// ProjectRoots возвращает каталоги, в которых ищутся проекты по имени (с раскрытой ~).
+// По умолчанию это только домашняя директория.
+func (fc *FavouritesConfig) ProjectRoots() []string {
+	if len(fc.Roots) == 0 {
+		return []string{Home}
+	}
+	roots := make([]string, 0, len(fc.Roots))
+	for _, r := range fc.Roots {
+		roots = append(roots, expandPath(r))
+	}
+	return roots
+}
+
+// AddRoot добавляет каталог с проектами в roots, если его там ещё нет.
+// Пока roots пуст, проекты ищутся в домашней директории; чтобы этот поиск не пропал
+// после добавления первого каталога, домашняя директория записывается в roots явно (как ~).
+// Возвращает true, если конфиг изменился.
+func (fc *FavouritesConfig) AddRoot(root string) bool {
+	root = normalizePath(root)
+	if root == "" {
+		return false
+	}
+	home := normalizePath(Home)
+	if len(fc.Roots) == 0 {
+		if root == home {
+			return false
+		}
+		fc.Roots = append(fc.Roots, "~")
+	}
+	for _, r := range fc.Roots {
+		if normalizePath(r) == root {
+			return false
+		}
+	}
+	if root == home {
+		fc.Roots = append(fc.Roots, "~")
+	} else {
+		fc.Roots = append(fc.Roots, root)
+	}
+	fc.changed = true
+	return true
+}
+
+// RecencyWeight возвращает вес недавности сессии при нечётком поиске
+func (fc *FavouritesConfig) RecencyWeight() float64 {
+	if fc.FuzzyRecencyWeight == nil {
+		return 1
+	}
+	return *fc.FuzzyRecencyWeight
+}
+
+// CanAutoCreateTmp возвращает true, если несуществующие каталоги внутри /tmp можно создавать без флага -c
+func (fc *FavouritesConfig) CanAutoCreateTmp() bool {
+	return fc.AutoCreateTmp == nil || *fc.AutoCreateTmp
+}
+
+// TogglePinned закрепляет сессию в начале списка или снимает закрепление, если она уже закреплена
+func (fc *FavouritesConfig) TogglePinned(name string) {
+	for i, p := range fc.PinnedSessions {
+		if p == name {
+			fc.PinnedSessions = append(fc.PinnedSessions[:i], fc.PinnedSessions[i+1:]...)
+			fc.changed = true
+			info("unpinned %s", name)
+			return
+		}
+	}
+	fc.PinnedSessions = append(fc.PinnedSessions, name)
+	fc.changed = true
+	info("pinned %s", name)
+}
+
+// RecordLastAttached запоминает сессию current, с которой переключаются на name, для pr -b.
+// Переключение на ту же сессию (и неизвестная текущая сессия) ничего не меняет,
+// чтобы pr -b не возвращал на ту сессию, где пользователь уже находится.
+func (fc *FavouritesConfig) RecordLastAttached(current string, name string) {
+	if current == "" || current == name || fc.LastAttached == current {
+		return
+	}
+	fc.LastAttached = current
+	fc.changed = true
+}
+
 // Touch добавляет сессию в историю сессий (или переставляет её на первую позицию, если сессия уже была там)
 func (fc *FavouritesConfig) Touch(name string, path string) {
-	if strings.HasPrefix(path, "/tmp/") {
+	if isEphemeralPath(path) {
 		// не будем сохранять временные сессии в конфиге
 		return
 	}
@@ -192,12 +513,16 @@ func (fc *FavouritesConfig) Touch(name string, path string) {
 		}
 	}
 
+	now := time.Now()
 	if found_i == 0 {
-		// nothing to change
+		// порядок не меняется, только время
+		fc.Sessions[0].LastUsed = &now
+		fc.changed = true
 		return
 	} else if found_i > 0 {
 		fs = fc.Sessions[found_i]
 	}
+	fs.LastUsed = &now
 	// переставляем сессию на позицию 0
 	newOrder := make([]FavouriteSession, 0, len(fc.Sessions)+1)
 	newOrder = append(newOrder, fs)
@@ -211,27 +536,222 @@ func (fc *FavouritesConfig) Touch(name string, path string) {
 	fc.changed = true
 }
 
+// touchSession запоминает сессию в истории, если это не выключено флагом -no-touch или опцией touch_on_switch
+func touchSession(name string, path string) {
+	if *fNoTouch || (Config.TouchOnSwitch != nil && !*Config.TouchOnSwitch) {
+		return
+	}
+	Config.Touch(name, path)
+}
+
+// Find возвращает сессию из конфига с указанным именем или nil, если такой нет
+func (fc *FavouritesConfig) Find(name string) *FavouriteSession {
+	for i := range fc.Sessions {
+		if fc.Sessions[i].Name == name {
+			return &fc.Sessions[i]
+		}
+	}
+	return nil
+}
+
+// FindStartupByPath возвращает сессию из конфига с каталогом path, для которой задана
+// команда, окружение или раскладка, либо nil. Записи истории без настроек пропускаются.
+func (fc *FavouritesConfig) FindStartupByPath(path string) *FavouriteSession {
+	path = normalizePath(path)
+	for i := range fc.Sessions {
+		fs := &fc.Sessions[i]
+		if normalizePath(fs.Path) != path {
+			continue
+		}
+		if fs.Cmd != "" || len(fs.Env) > 0 || len(fs.Layout) > 0 {
+			return fs
+		}
+	}
+	return nil
+}
+
+// FindByNameOrAlias возвращает сессию из конфига с указанным именем или алиасом либо nil
+func (fc *FavouritesConfig) FindByNameOrAlias(id string) *FavouriteSession {
+	if fs := fc.Find(id); fs != nil {
+		return fs
+	}
+	for i := range fc.Sessions {
+		for _, a := range fc.Sessions[i].Aliases {
+			if a == id {
+				return &fc.Sessions[i]
+			}
+		}
+	}
+	return nil
+}
+
+// SetRoot меняет каталог сессии в конфиге, не трогая имя, алиасы, команду и окружение
+func (fc *FavouritesConfig) SetRoot(id string, path string) error {
+	fs := fc.FindByNameOrAlias(id)
+	if fs == nil {
+		return fmt.Errorf("session %s not found in config", id)
+	}
+	path, err := filepath.Abs(expandHome(path))
+	if err != nil {
+		return err
+	}
+	if !isDir(path) {
+		return fmt.Errorf("cannot set root of %s to %s: directory does not exist", fs.Name, path)
+	}
+	if fs.Path == path {
+		return nil
+	}
+	fs.Path = path
+	fc.changed = true
+	return nil
+}
+
+// Rename переименовывает сессию в конфиге, сохраняя её алиасы, команду и окружение.
+// Имя, уже занятое другой сессией из конфига, - ошибка: иначе одна из двух сессий
+// с одинаковым именем стала бы недоступна через Find.
+func (fc *FavouritesConfig) Rename(oldName string, newName string) error {
+	fs := fc.Find(oldName)
+	if fs == nil || oldName == newName {
+		return nil
+	}
+	if fc.Find(newName) != nil {
+		return fmt.Errorf("cannot rename %s to %s: saved session %s already exists", oldName, newName, newName)
+	}
+	fs.Name = newName
+	for i, h := range fc.History {
+		if h == oldName {
+			fc.History[i] = newName
+		}
+	}
+	fc.changed = true
+	return nil
+}
+
+// AddAlias добавляет алиас к сессии в конфиге (если такого алиаса у неё ещё нет)
+func (fc *FavouritesConfig) AddAlias(name string, alias string) {
+	fs := fc.Find(name)
+	if fs == nil {
+		return
+	}
+	for _, a := range fs.Aliases {
+		if a == alias {
+			return
+		}
+	}
+	fs.Aliases = append(fs.Aliases, alias)
+	fc.changed = true
+}
+
+// IsFavourite возвращает true, если сессия настроена вручную (есть команда, окружение, алиасы или раскладка),
+// а не просто попала в историю
+func (f *FavouriteSession) IsFavourite() bool {
+	return f.Cmd != "" || len(f.Env) > 0 || len(f.Aliases) > 0 || len(f.Layout) > 0
+}
+
+// ClearHistory удаляет из конфига сессии, которые попали туда только как история
+func (fc *FavouritesConfig) ClearHistory() {
+	kept := make([]FavouriteSession, 0, len(fc.Sessions))
+	for _, fs := range fc.Sessions {
+		if fs.IsFavourite() {
+			kept = append(kept, fs)
+		}
+	}
+	if len(kept) != len(fc.Sessions) {
+		fc.Sessions = kept
+		fc.changed = true
+	}
+}
+
+// PruneMissing удаляет из конфига сессии, каталогов которых больше нет (кроме временных),
+// и возвращает удалённые сессии. С dryRun только возвращает их, не меняя конфиг.
+func (fc *FavouritesConfig) PruneMissing(dryRun bool) []FavouriteSession {
+	kept := make([]FavouriteSession, 0, len(fc.Sessions))
+	removed := []FavouriteSession{}
+	for _, fs := range fc.Sessions {
+		if !isTemporaryPath(fs.ExpandedPath()) && !isDir(fs.ExpandedPath()) {
+			removed = append(removed, fs)
+			continue
+		}
+		kept = append(kept, fs)
+	}
+	if len(removed) > 0 && !dryRun {
+		fc.Sessions = kept
+		fc.changed = true
+	}
+	return removed
+}
+
 // TmuxSession возвращает полузаполненный объект TmuxSession
 func (f *FavouriteSession) TmuxSession() TmuxSession {
-	return TmuxSession{
+	s := TmuxSession{
 		Name: f.Name,
-		Path: f.Path,
+		Path: f.ExpandedPath(),
+	}
+	if f.LastUsed != nil {
+		s.LastActivity = *f.LastUsed
 	}
+	return s
 }
 
-// listSessions возвращает список имеющихся сессий tmux
+// Разделители полей и строк в выводе tmux -F. Управляющие символы не встречаются
+// в именах сессий и путях на практике, в отличие от табуляций и переводов строк.
+const (
+	tmuxFieldSep  = "\x1f"
+	tmuxRecordSep = "\x1e"
+)
+
+// noTmuxServer равен true, если при запуске pr сервер tmux не был запущен
+var noTmuxServer bool
+
+// listSessions возвращает список имеющихся сессий tmux.
+// tmux запускается с -u: без UTF-8 в локали (например, при пустом LANG) tmux заменяет
+// управляющие символы в выводе на "_", и разделители tmuxFieldSep/tmuxRecordSep пропали бы.
 func listSessions() []TmuxSession {
-	out, err := exec.Command("tmux", "list-sessions", "-F", "#S\t#{session_path}\t#{session_attached}\t#{session_windows}\t#{session_activity}").CombinedOutput()
+	format := strings.Join([]string{
+		"#S",
+		"#{session_path}",
+		"#{session_attached}",
+		"#{session_windows}",
+		"#{session_activity}",
+		"#{session_created}",
+	}, tmuxFieldSep) + tmuxRecordSep
+	out, err := exec.Command("tmux", "-u", "list-sessions", "-F", format).CombinedOutput()
 	if err != nil {
-		log.Printf("tmux list-sessions: %s: %s", err, out)
+		noTmuxServer = strings.Contains(string(out), "no server running") || strings.Contains(string(out), "error connecting to")
+		warn("tmux list-sessions: %s: %s", err, out)
 		return []TmuxSession{}
 	}
+	return parseSessions(string(out))
+}
+
+// tmuxState это состояние tmux, прочитанное один раз за запуск pr: список сессий
+// и те же сессии по именам. Позволяет не вызывать tmux list-sessions повторно.
+type tmuxState struct {
+	sessions []TmuxSession
+	byName   map[string]TmuxSession
+}
+
+// newTmuxState читает список сессий из tmux
+func newTmuxState() *tmuxState {
+	return tmuxStateOf(listSessions())
+}
+
+// tmuxStateOf строит состояние tmux из уже прочитанного списка сессий
+func tmuxStateOf(sessions []TmuxSession) *tmuxState {
+	byName := make(map[string]TmuxSession, len(sessions))
+	for _, s := range sessions {
+		byName[s.Name] = s
+	}
+	return &tmuxState{sessions: sessions, byName: byName}
+}
 
+// parseSessions разбирает вывод tmux list-sessions в формате из listSessions
+func parseSessions(out string) []TmuxSession {
 	sessions := []TmuxSession{}
 
-	for _, line := range strings.Split(string(out), "\n") {
-		parts := strings.Split(line, "\t")
-		if len(parts) == 5 {
+	for _, record := range strings.Split(out, tmuxRecordSep+"\n") {
+		parts := strings.Split(record, tmuxFieldSep)
+		if len(parts) == 6 {
 			s := TmuxSession{
 				Name:     parts[0],
 				Path:     parts[1],
@@ -246,6 +766,10 @@ func listSessions() []TmuxSession {
 			if err == nil {
 				s.LastActivity = time.Unix(int64(ts), 0)
 			}
+			ts, err = strconv.ParseInt(parts[5], 10, 64)
+			if err == nil {
+				s.Created = time.Unix(int64(ts), 0)
+			}
 
 			sessions = append(sessions, s)
 		}
@@ -254,37 +778,166 @@ func listSessions() []TmuxSession {
 	return sessions
 }
 
+// findLiveSession ищет живую сессию по точному имени или по префиксу имени
+func findLiveSession(sessions []TmuxSession, sessionId string) (TmuxSession, bool) {
+	for _, s := range sessions {
+		if s.Name == sessionId {
+			return s, true
+		}
+	}
+	for _, s := range sessions {
+		if strings.HasPrefix(s.Name, sessionId) {
+			return s, true
+		}
+	}
+	return TmuxSession{}, false
+}
+
 // createSession создаёт сессию с указанным именем и рабочим каталогом и переключается на неё
-func createSession(name string, path string, startCmd string, env map[string]string) {
+func createSession(name string, path string, startCmd string, env map[string]string, layout []LayoutWindow, defaultWindow int) {
 	args := []string{"new", "-c", path, "-s", name, "-d"}
-	for k, v := range env {
-		args = append(args, "-e", fmt.Sprintf("%s=%s", k, v))
+	envArgs, err := tmuxEnvArgs(env)
+	if err != nil {
+		fatalf("cannot create session %s: %s", name, err)
+	}
+	args = append(args, envArgs...)
+	if len(layout) > 0 && layout[0].Cmd != "" {
+		// команда первого окна из раскладки заменяет команду сессии
+		startCmd = layout[0].Cmd
 	}
 	if startCmd != "" {
 		// это последний аргумент при вызове
 		args = append(args, startCmd)
 	}
-	_, err := exec.Command("tmux", args...).Output()
+	out, err := exec.Command("tmux", args...).Output()
+	logCommand(name, args, err, out)
 	dieIfError(err)
+	if noTmuxServer {
+		// сессия создана, значит вместе с ней запустился и сервер
+		noTmuxServer = false
+		runOnServerStart()
+	}
+	applyLayout(name, path, layout, defaultWindow)
+}
+
+// envNameRe это допустимое имя переменной окружения
+var envNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// tmuxEnvArgs возвращает аргументы -e KEY=VALUE для tmux new-session, отсортированные по имени
+// переменной, чтобы команда создания сессии всегда была одинаковой. Недопустимые имена - ошибка.
+func tmuxEnvArgs(env map[string]string) ([]string, error) {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		if !envNameRe.MatchString(k) {
+			return nil, fmt.Errorf("invalid environment variable name %q", k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	args := make([]string, 0, 2*len(keys))
+	for _, k := range keys {
+		args = append(args, "-e", k+"="+env[k])
+	}
+	return args, nil
+}
+
+// runOnServerStart выполняет команду on_server_start из конфига. Ошибки не фатальны.
+func runOnServerStart() {
+	if Config.OnServerStart == "" {
+		return
+	}
+	out, err := exec.Command("sh", "-c", Config.OnServerStart).CombinedOutput()
+	if err != nil {
+		log.Printf("on_server_start failed: %s: %s", err, strings.TrimSpace(string(out)))
+	}
+}
+
+// windowSizeModes это допустимые значения опции tmux window-size
+var windowSizeModes = []string{"largest", "smallest", "manual", "latest"}
+
+// applyWindowSizeMode устанавливает сессии опцию window-size из конфига (если она задана),
+// чтобы размер окон определял нужный клиент
+func applyWindowSizeMode(name string) {
+	mode := Config.WindowSizeMode
+	if mode == "" {
+		return
+	}
+	valid := false
+	for _, m := range windowSizeModes {
+		if m == mode {
+			valid = true
+		}
+	}
+	if !valid {
+		warn("invalid window_size_mode %q in config: must be one of %s", mode, strings.Join(windowSizeModes, ", "))
+		return
+	}
+	out, err := exec.Command("tmux", "set-option", "-t", name, "window-size", mode).CombinedOutput()
+	if err != nil {
+		warn("cannot set window-size for %s: %s: %s", name, err, strings.TrimSpace(string(out)))
+	}
 }
 
 // switchToSession переключается на сессию с указанным именем
 func switchToSession(name string) {
+	applyWindowSizeMode(name)
+	renameWindowOnSwitch(name)
+	if *fNewClient {
+		recordSwitch(name)
+		openInNewTerminal(name)
+		return
+	}
+	if *fWindow && os.Getenv("TMUX") != "" {
+		openAsWindow(name)
+		return
+	}
+	recordSwitch(name)
 	if os.Getenv("TMUX") != "" {
+		Config.RecordLastAttached(getCurrentSessionName(), name)
 		out, err := exec.Command("tmux", "switch-client", "-t", name).CombinedOutput()
 		if err != nil {
 			log.Printf("failed: %s", string(out))
 			dieIfError(err)
 		}
 	} else {
+		if inBatch {
+			// attach заменил бы процесс pr, и остальные строки не выполнились бы
+			fatalf("cannot attach to %s in -batch outside tmux: use -no-switch or run inside tmux", name)
+		}
 		tmuxPath, err := exec.LookPath("tmux")
 		dieIfError(err)
+		// процесс будет заменён на tmux, поэтому сохраняем конфиг заранее
+		Config.Save()
 		env := os.Environ()
 		err = syscall.Exec(tmuxPath, []string{"tmux", "attach", "-t", name}, env)
 		dieIfError(err)
 	}
 }
 
+// killSession завершает сессию с указанным именем
+func killSession(name string) error {
+	out, err := exec.Command("tmux", "kill-session", "-t", name).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("tmux kill-session %s: %s: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// openInNewTerminal запускает терминал из конфига (terminal) с tmux attach к указанной сессии
+func openInNewTerminal(name string) {
+	terminal := strings.Fields(Config.Terminal)
+	if len(terminal) == 0 {
+		fatalf("cannot open a new client: terminal is not set in config (e.g. \"terminal\": \"alacritty -e\")")
+	}
+	args := append(terminal[1:], "tmux", "attach", "-t", name)
+	cmd := exec.Command(terminal[0], args...)
+	err := cmd.Start()
+	if err != nil {
+		fatalf("cannot start terminal %s: %s", terminal[0], err)
+	}
+	dieIfError(cmd.Process.Release())
+}
+
 // getSessionPath возвращает каталог, с которым была запущена текущая сессия
 func getSessionPath() string {
 	// tmux display-message -p '#{session_path}'
@@ -294,6 +947,13 @@ func getSessionPath() string {
 	return strings.TrimSpace(p)
 }
 
+// getCurrentSessionName возвращает имя текущей сессии
+func getCurrentSessionName() string {
+	out, err := exec.Command("tmux", "display-message", "-p", "#S").Output()
+	dieIfError(err)
+	return strings.TrimSpace(string(out))
+}
+
 // getTodoFilename возвращает путь к файлу TODO в указанном проекте
 func getTodoFilename(dir string) string {
 	return filepath.Join(dir, ".todo")
@@ -310,6 +970,31 @@ func getTodoContents(dir string) string {
 	return ""
 }
 
+// normalizePath приводит путь к виду, пригодному для сравнения: раскрывает ~,
+// убирает завершающий слэш и лишние элементы пути
+func normalizePath(p string) string {
+	if p == "" {
+		return ""
+	}
+	return filepath.Clean(expandPath(p))
+}
+
+// expandPath раскрывает переменные окружения ($HOME, ${VAR}) и ~ в начале пути из конфига
+func expandPath(p string) string {
+	return expandHome(os.ExpandEnv(p))
+}
+
+// expandHome раскрывает ~ в начале пути
+func expandHome(p string) string {
+	if p == "~" {
+		return Home
+	}
+	if strings.HasPrefix(p, "~/") {
+		return filepath.Join(Home, p[2:])
+	}
+	return p
+}
+
 // isDir возвращает true, если path это существующий каталог
 func isDir(path string) bool {
 	if s, err := os.Stat(path); err == nil {
@@ -330,11 +1015,38 @@ func isFile(path string) bool {
 func readLine() string {
 	s := bufio.NewScanner(os.Stdin)
 	if ok := s.Scan(); !ok {
-		log.Fatal(s.Err())
+		fatal(s.Err())
 	}
 	return s.Text()
 }
 
+// confirm задаёт пользователю вопрос и возвращает true, если он ответил "y".
+// С флагом -y всегда возвращает true; без терминала на stdin (и без -y) завершает программу.
+func confirm(question string) bool {
+	if *fYes {
+		return true
+	}
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		fatalf("%s: stdin is not a terminal, use -y to confirm", question)
+	}
+	fmt.Printf("%s [y/N]: ", question)
+	answer := strings.ToLower(strings.TrimSpace(readLine()))
+	return answer == "y" || answer == "yes"
+}
+
+// askSessionName спрашивает у пользователя имя для новой сессии; пустой ввод оставляет defaultName
+func askSessionName(sessionsByName map[string]TmuxSession, defaultName string) string {
+	fmt.Printf("session name [%s]: ", defaultName)
+	name := strings.TrimSpace(readLine())
+	if name == "" {
+		return defaultName
+	}
+	if _, ok := sessionsByName[name]; ok {
+		fatalf("session %s already exists", name)
+	}
+	return name
+}
+
 // countRepeatedChars возвращает длину строки, если строка состоит только из
 // символов char; иначе возвращает 9
 func countRepeatedChars(s string, char rune) int {
@@ -346,37 +1058,65 @@ func countRepeatedChars(s string, char rune) int {
 	return len(s)
 }
 
-// createTemporaryProject создаёт временную папку в tmp и возвращает её путь
-func createTemporaryProject() string {
+// createTemporaryProject создаёт временную папку в tmp_root и возвращает её путь.
+// Без имени папки называются tN, с именем name - name, name1, name2 и т.д. (первое свободное).
+func createTemporaryProject(name string) string {
 	maxNumber := 1024
 	for i := 0; i < maxNumber; i++ {
-		path := fmt.Sprintf("/tmp/t%d", i)
+		dir := fmt.Sprintf("t%d", i)
+		if name != "" {
+			dir = name
+			if i > 0 {
+				dir = fmt.Sprintf("%s%d", name, i)
+			}
+		}
+		path := filepath.Join(tmpRoot(), dir)
 		err := os.Mkdir(path, 0750)
 		if err != nil && os.IsExist(err) {
 			continue
 		}
 		if err != nil {
-			log.Fatalf("cannot create temporary directory: %s", err)
+			fatalf("cannot create temporary directory: %s", err)
 		}
 		return path
 	}
-	log.Fatalf("reached max number of temporary projects (%d). Please clean your /tmp/t* folders.", maxNumber)
+	if name == "" {
+		name = "t"
+	}
+	fatalf("reached max number of temporary projects (%d). Please clean your %s/%s* folders.", maxNumber, tmpRoot(), name)
 	return ""
 }
 
-var SUFFIXES = []string{"", "1", "2", "3", "4", "5", "6", "7", "8", "9"}
-
-// ChangeSession переключается на сессию sessionId, создавая её, если её ещё нет
-func ChangeSession(sessions []TmuxSession, sessionId string, allowCreateDir bool) {
-	sessionsByName := make(map[string]TmuxSession)
-	for _, s := range sessions {
-		sessionsByName[s.Name] = s
+// suffixedName возвращает i-й вариант имени сессии при коллизиях: name, name1, name2, ...
+func suffixedName(name string, i int) string {
+	if i == 0 {
+		return name
 	}
+	return name + strconv.Itoa(i)
+}
+
+// sessionTarget это сессия, на которую указывает идентификатор, переданный пользователем
+type sessionTarget struct {
+	Name          string
+	Path          string
+	StartCmd      string
+	Env           map[string]string
+	Layout        []LayoutWindow
+	DefaultWindow int
+	CreateDir     bool // каталог Path ещё не существует и должен быть создан
+}
 
+// resolveTarget находит имя и каталог сессии по идентификатору sessionId
+// (варианты идентификатора перечислены в начале файла). Сам ничего не создаёт.
+func resolveTarget(ts *tmuxState, sessionId string, allowCreateDir bool) (sessionTarget, error) {
+	sessions := ts.sessions
 	sessionDirPath := ""
 	sessionName := ""
 	sessionStartCmd := ""
 	var sessionEnv map[string]string = nil
+	var sessionLayout []LayoutWindow = nil
+	sessionDefaultWindow := 0
+	createDir := false
 
 	if sessionId == "." {
 		x, err := os.Getwd()
@@ -386,15 +1126,14 @@ func ChangeSession(sessions []TmuxSession, sessionId string, allowCreateDir bool
 	if strings.HasPrefix(sessionId, "/") {
 		if !isDir(sessionId) {
 			if isDir(filepath.Dir(sessionId)) {
-				if allowCreateDir || strings.HasPrefix(sessionId, "/tmp/") {
-					err := os.Mkdir(sessionId, os.ModePerm)
-					dieIfError(err)
+				if allowCreateDir || (isTemporaryPath(sessionId) && Config.CanAutoCreateTmp()) {
+					createDir = true
 					sessionDirPath = sessionId
 				} else {
-					log.Fatalf("cannot switch to %s (directory does not exist): use -c flag to create a new directory", sessionId)
+					return sessionTarget{}, fmt.Errorf("cannot switch to %s (directory does not exist): use -c flag to create a new directory", sessionId)
 				}
 			} else {
-				log.Fatalf("cannot switch to %s: looks like a dir but does not exist and cannot be created", sessionId)
+				return sessionTarget{}, fmt.Errorf("cannot switch to %s: looks like a dir but does not exist and cannot be created", sessionId)
 			}
 		} else {
 			sessionDirPath = sessionId
@@ -403,51 +1142,66 @@ func ChangeSession(sessions []TmuxSession, sessionId string, allowCreateDir bool
 	} else if n := countRepeatedChars(sessionId, '-'); n > 0 {
 		// переключаемся на предпоследнюю, или пред-предпоследнюю, или пред-пред<...> сессию
 		if len(sessions) < 2 {
-			log.Fatalf("cannot switch to a previous session (too few sessions)")
+			return sessionTarget{}, fmt.Errorf("cannot switch to a previous session (too few sessions)")
 		}
 		if n >= len(sessions) {
 			n = len(sessions) - 1
 		}
-		sortedSessions := make([]TmuxSession, len(sessions))
-		copy(sortedSessions, sessions)
-		sort.Slice(sortedSessions, func(i, j int) bool {
-			return sortedSessions[i].LastActivity.After(sortedSessions[j].LastActivity)
-		})
-		s := sortedSessions[n]
+		s := sortByActivity(sessions)[n]
 		sessionName = s.Name
 		sessionDirPath = s.Path
 	} else {
 		// ищем по точному совпадению
-		if s, ok := sessionsByName[sessionId]; ok {
+		if s, ok := ts.byName[sessionId]; ok {
 			sessionName = s.Name
 			sessionDirPath = s.Path
 		}
 		if sessionName == "" {
-			// попробуем найти по префиксу
-			for _, s := range sessions {
-				if strings.HasPrefix(s.Name, sessionId) {
-					sessionName = s.Name
-					sessionDirPath = s.Path
+			// попробуем найти по префиксу; если подходит несколько сессий, не угадываем.
+			// Сначала с учётом регистра, затем без
+			for _, hasPrefix := range []func(string, string) bool{strings.HasPrefix, hasPrefixFold} {
+				matches := []string{}
+				for _, s := range sessions {
+					if hasPrefix(s.Name, sessionId) {
+						matches = append(matches, s.Name)
+						sessionName = s.Name
+						sessionDirPath = s.Path
+					}
+				}
+				if len(matches) > 1 {
+					return sessionTarget{}, fmt.Errorf("%s is ambiguous, matching sessions: %s", sessionId, strings.Join(matches, ", "))
+				}
+				if sessionName != "" {
+					break
 				}
 			}
 		}
 		if sessionName == "" {
 			// заглянем в конфиг и найдём каталог из "избранного"
-			// по полному совпадению имени или алиаса
-			for _, fs := range Config.Sessions {
-				if fs.Name == sessionId {
-					sessionName = fs.Name
-					sessionDirPath = fs.Path
-					sessionStartCmd = fs.Cmd
-					sessionEnv = fs.Env
-					break
-				}
-				for _, a := range fs.Aliases {
-					if a == sessionId {
+			// по полному совпадению имени или алиаса, сначала с учётом регистра, затем без
+			for _, equal := range []func(string, string) bool{equalExact, strings.EqualFold} {
+				for _, fs := range Config.Sessions {
+					if equal(fs.Name, sessionId) {
 						sessionName = fs.Name
-						sessionDirPath = fs.Path
+						sessionDirPath = fs.ExpandedPath()
 						sessionStartCmd = fs.Cmd
 						sessionEnv = fs.Env
+						sessionLayout = fs.Layout
+						sessionDefaultWindow = fs.DefaultWindow
+						break
+					}
+					for _, a := range fs.Aliases {
+						if equal(a, sessionId) {
+							sessionName = fs.Name
+							sessionDirPath = fs.ExpandedPath()
+							sessionStartCmd = fs.Cmd
+							sessionEnv = fs.Env
+							sessionLayout = fs.Layout
+							sessionDefaultWindow = fs.DefaultWindow
+							break
+						}
+					}
+					if sessionName != "" {
 						break
 					}
 				}
@@ -462,9 +1216,11 @@ func ChangeSession(sessions []TmuxSession, sessionId string, allowCreateDir bool
 			for _, fs := range Config.Sessions {
 				if strings.HasPrefix(fs.Name, sessionId) {
 					sessionName = fs.Name
-					sessionDirPath = fs.Path
+					sessionDirPath = fs.ExpandedPath()
 					sessionStartCmd = fs.Cmd
 					sessionEnv = fs.Env
+					sessionLayout = fs.Layout
+					sessionDefaultWindow = fs.DefaultWindow
 					break
 				}
 			}
@@ -473,54 +1229,186 @@ func ChangeSession(sessions []TmuxSession, sessionId string, allowCreateDir bool
 		}
 	}
 	if sessionName == "" {
-		// попробуем найти каталог в домашней директории, по точному совпадению
-		p := filepath.Join(Home, sessionId)
-		if isDir(p) {
-			sessionDirPath = p
-			sessionName = filepath.Base(sessionDirPath)
+		// попробуем найти каталог в корневых каталогах проектов (по умолчанию в домашней), по точному совпадению
+		for _, root := range Config.ProjectRoots() {
+			p := filepath.Join(root, sessionId)
+			if isDir(p) {
+				sessionDirPath = p
+				sessionName = filepath.Base(sessionDirPath)
+				break
+			}
 		}
 	}
 	if sessionName == "" {
-		// попробуем найти каталог в домашней директории, по префиксу
-		entries, err := os.ReadDir(Home)
-		dieIfError(err)
-		for _, e := range entries {
-			if strings.HasPrefix(e.Name(), sessionId) {
-				p := filepath.Join(Home, e.Name())
-				if isDir(p) {
-					sessionDirPath = p
-					sessionName = filepath.Base(sessionDirPath)
-					break
+		// попробуем найти каталог в корневых каталогах проектов по префиксу (сначала с учётом регистра).
+		// Если подходят каталоги в разных корнях, не угадываем
+		for _, hasPrefix := range []func(string, string) bool{strings.HasPrefix, hasPrefixFold} {
+			matches := []string{}
+			for _, root := range Config.ProjectRoots() {
+				if p := findDirByPrefix(root, sessionId, hasPrefix); p != "" {
+					matches = append(matches, p)
 				}
 			}
+			if len(matches) > 1 {
+				return sessionTarget{}, fmt.Errorf("%s is ambiguous, matching directories: %s", sessionId, strings.Join(matches, ", "))
+			}
+			if len(matches) == 1 {
+				sessionDirPath = matches[0]
+				sessionName = filepath.Base(sessionDirPath)
+				break
+			}
 		}
 	}
 	if sessionName == "" {
-		log.Fatalf("directory ~/%s* does not exist", sessionId)
+		// поищем среди недавних каталогов из истории shell
+		if p := findRecentDir(sessionId); p != "" {
+			sessionDirPath = p
+			sessionName = filepath.Base(sessionDirPath)
+		}
 	}
-
-	// подберём имя сессии с суффиксом во избежание коллизий
-	for i := 0; i < len(SUFFIXES); i++ {
-		_name := sessionName + SUFFIXES[i]
-		s, ok := sessionsByName[_name]
-		if ok && s.Path == sessionDirPath {
-			Config.Touch(s.Name, s.Path)
-			switchToSession(s.Name)
-			return
+	if sessionName == "" {
+		// ничего не нашлось ни по имени, ни по префиксу: последняя попытка - нечёткий поиск
+		if t, ok, err := fuzzyResolve(sessions, sessionId); err != nil {
+			return sessionTarget{}, err
+		} else if ok {
+			return t, nil
 		}
+		dirs := []string{}
+		for _, root := range Config.ProjectRoots() {
+			dirs = append(dirs, filepath.Join(root, sessionId)+"*")
+		}
+		return sessionTarget{}, fmt.Errorf("directory %s does not exist", strings.Join(dirs, ", "))
+	}
+	return sessionTarget{
+		Name:          sessionName,
+		Path:          sessionDirPath,
+		StartCmd:      sessionStartCmd,
+		Env:           sessionEnv,
+		Layout:        sessionLayout,
+		DefaultWindow: sessionDefaultWindow,
+		CreateDir:     createDir,
+	}, nil
+}
+
+// findDirByPrefix возвращает первый подкаталог root, имя которого начинается с prefix
+// (сравнение задаётся hasPrefix), или пустую строку
+func findDirByPrefix(root string, prefix string, hasPrefix func(string, string) bool) string {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			warn("cannot read %s: %s", root, err)
+		}
+		return ""
+	}
+	for _, e := range entries {
+		if hasPrefix(e.Name(), prefix) {
+			p := filepath.Join(root, e.Name())
+			if isDir(p) {
+				return p
+			}
+		}
+	}
+	return ""
+}
+
+// equalExact сравнивает строки с учётом регистра
+func equalExact(a string, b string) bool {
+	return a == b
+}
+
+// hasPrefixFold это strings.HasPrefix без учёта регистра
+func hasPrefixFold(s string, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// isPlainName возвращает true, если sessionId можно использовать как имя каталога
+func isPlainName(sessionId string) bool {
+	return sessionId != "" && sessionId != "." && sessionId != ".." && !strings.ContainsRune(sessionId, '/') && countRepeatedChars(sessionId, '-') == 0
+}
+
+// scratchTarget возвращает новый проект с указанным именем в каталоге scratch_root
+func scratchTarget(name string) sessionTarget {
+	root := filepath.Join(Home, "scratch")
+	if Config.ScratchRoot != "" {
+		root = expandPath(Config.ScratchRoot)
+	}
+	p := filepath.Join(root, name)
+	return sessionTarget{Name: name, Path: p, CreateDir: !isDir(p)}
+}
+
+// withStartupFromConfig дополняет сессию командой, окружением и раскладкой из записи конфига
+// с тем же каталогом, если сама сессия найдена не через конфиг (например, по пути).
+// Так проект запускается одинаково, как бы к нему ни обратились.
+func withStartupFromConfig(target sessionTarget) sessionTarget {
+	if target.StartCmd != "" || len(target.Env) > 0 || len(target.Layout) > 0 {
+		return target
+	}
+	fs := Config.FindStartupByPath(target.Path)
+	if fs == nil {
+		return target
+	}
+	target.StartCmd = fs.Cmd
+	target.Env = fs.Env
+	target.Layout = fs.Layout
+	target.DefaultWindow = fs.DefaultWindow
+	return target
+}
+
+// ChangeSession переключается на сессию sessionId, создавая её, если её ещё нет
+func ChangeSession(ts *tmuxState, sessionId string, allowCreateDir bool) {
+	if inBatch && os.Getenv("TMUX") == "" && !*fNewClient {
+		// проверяем до поиска и создания сессии, чтобы неудачная строка ничего не меняла
+		fatalf("cannot switch to %s in -batch outside tmux", sessionId)
+	}
+	target, err := resolveTarget(ts, sessionId, allowCreateDir)
+	if err != nil && *fCreateScratch && isPlainName(sessionId) {
+		target = scratchTarget(sessionId)
+		err = nil
+	}
+	if err != nil {
+		fatal(err)
+	}
+	if target.CreateDir {
+		err := os.MkdirAll(target.Path, os.ModePerm)
+		dieIfError(err)
+	}
+
+	sessionName := target.Name
+	sessionDirPath := target.Path
+
+	name, exists := chooseSessionName(ts.byName, sessionName, sessionDirPath)
+	if exists {
+		touchSession(name, sessionDirPath)
+		switchToSession(name)
+		return
+	}
+	if *fAskName {
+		name = askSessionName(ts.byName, name)
+	}
+	target = withStartupFromConfig(target)
+	if Config.DetectProjectType {
+		target = withProjectTypeDefaults(target)
+	}
+	touchSession(name, sessionDirPath)
+	createSession(name, sessionDirPath, target.StartCmd, target.Env, target.Layout, target.DefaultWindow)
+	switchToSession(name)
+}
+
+// chooseSessionName подбирает имя сессии для каталога path во избежание коллизий:
+// name, name1, name2, ... Если среди вариантов имени есть живая сессия в том же каталоге,
+// возвращает её имя и exists = true; иначе первое свободное имя.
+// Цикл конечен: живых сессий конечное число, и свободное имя всегда найдётся
+func chooseSessionName(byName map[string]TmuxSession, name string, path string) (string, bool) {
+	for i := 0; ; i++ {
+		candidate := suffixedName(name, i)
+		s, ok := byName[candidate]
 		if !ok {
-			Config.Touch(sessionName, sessionDirPath)
-			createSession(sessionName, sessionDirPath, sessionStartCmd, sessionEnv)
-			switchToSession(sessionName)
-			return
+			return candidate, false
+		}
+		if s.Path == path {
+			return candidate, true
 		}
 	}
-	log.Fatalf("cannot create session %s because names %s, %s..%s are occupied",
-		sessionName,
-		sessionName,
-		sessionName+SUFFIXES[1],
-		sessionName+SUFFIXES[len(SUFFIXES)-1],
-	)
 }
 
 // openTodoEditor открывает текстовый редактор для TODO-файла
@@ -530,68 +1418,268 @@ func openTodoEditor() {
 	openFileInEditor(fname)
 }
 
-// openFileInEditor открывает текстовый редактор с указанным файлом
-func openFileInEditor(filename string) {
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		editor = "nano"
+// resolveEditor разбирает $EDITOR (вместе с аргументами) и находит исполняемый файл редактора.
+// Если $EDITOR не задан или не найден, возвращает nano; в note записывается причина.
+func resolveEditor() (args []string, path string, note string) {
+	args = strings.Fields(os.Getenv("EDITOR"))
+	if len(args) == 0 {
+		note = "$EDITOR is not set, falling back to nano"
+	} else if p, err := exec.LookPath(args[0]); err == nil {
+		return args, p, ""
+	} else {
+		note = fmt.Sprintf("cannot locate $EDITOR (%s), falling back to nano", err)
 	}
-	editorPath, err := exec.LookPath(editor)
+	args = []string{"nano"}
+	path, err := exec.LookPath("nano")
 	if err != nil {
-		log.Fatalf("cannot locate editor: %s", err)
+		fatalf("cannot locate editor: %s; %s", err, note)
 	}
+	return args, path, note
+}
 
-	env := os.Environ()
-	err = syscall.Exec(editorPath, []string{editor, filename}, env)
-	dieIfError(err)
+// printEditor печатает команду редактора и путь к нему
+func printEditor() {
+	args, path, note := resolveEditor()
+	fmt.Printf("command: %s\n", strings.Join(args, " "))
+	fmt.Printf("path:    %s\n", path)
+	if note != "" {
+		fmt.Printf("note:    %s\n", note)
+	}
 }
 
-// printSessions выводит список сессий на экран
-func printSessions(sessions []TmuxSession, allColumns bool) {
-	cols := []interface{}{"name", "path", "windows", "activity", "attchd"}
-	if allColumns {
-		cols = append(cols, "todo")
+// openFileInEditor открывает текстовый редактор с указанным файлом
+func openFileInEditor(filename string) {
+	args, editorPath, note := resolveEditor()
+	if note != "" && os.Getenv("EDITOR") != "" {
+		warn("%s", note)
 	}
 
+	env := os.Environ()
+	err := syscall.Exec(editorPath, append(args, filename), env)
+	dieIfError(err)
+}
+
+// withSavedSessions дополняет список живых сессий сохранёнными в конфиге (неактивными) сессиями
+func withSavedSessions(sessions []TmuxSession) []TmuxSession {
 	allSessions := make([]TmuxSession, 0, len(sessions)+len(Config.Sessions))
 	allSessions = append(allSessions, sessions...)
 	sessionNames := make(map[string]bool)
+	sessionPaths := make(map[string]bool)
 	for _, s := range allSessions {
 		sessionNames[s.Name] = true
+		sessionPaths[normalizePath(s.Path)] = true
+	}
+	for _, fs := range Config.Sessions {
+		if sessionNames[fs.Name] {
+			continue
+		}
+		if *fNoSavedDups && sessionPaths[normalizePath(fs.Path)] {
+			// в этом каталоге уже открыта живая сессия под другим именем
+			continue
+		}
+		allSessions = append(allSessions, fs.TmuxSession())
 	}
+	return allSessions
+}
 
+// listedSessions возвращает сессии для вывода в списке: живые, сохранённые (с -a)
+// и недавние каталоги (в -interactive), отфильтрованные по -since и -min-windows, закреплённые - первыми
+func listedSessions(sessions []TmuxSession) []TmuxSession {
+	allSessions := sessions
 	if *fShowAllSessions {
-		for _, fs := range Config.Sessions {
-			if !sessionNames[fs.Name] {
-				allSessions = append(allSessions, fs.TmuxSession())
-			}
+		allSessions = withSavedSessions(sessions)
+	}
+	if *fInteractive {
+		allSessions = withRecentDirs(allSessions)
+	}
+	if *fGrep != "" {
+		re, err := regexp.Compile(*fGrep)
+		if err != nil {
+			fatalf("invalid -grep pattern %s: %s", *fGrep, err)
 		}
+		allSessions = withMatching(allSessions, re)
+	}
+	if *fSince != "" {
+		since, err := parseSince(*fSince, time.Now())
+		if err != nil {
+			fatal(err)
+		}
+		allSessions = sortByActivity(activeSince(allSessions, since))
+	}
+	if *fMinWindows > 0 {
+		allSessions = withMinWindows(allSessions, *fMinWindows)
+	}
+	allSessions, err := sortSessions(allSessions, *fSort)
+	if err != nil {
+		fatal(err)
+	}
+	return pinnedFirst(allSessions, Config.PinnedSessions)
+}
+
+// printSessions выводит список сессий на экран
+func printSessions(ts *tmuxState, allColumns bool) {
+	sessions := ts.sessions
+	allSessions := listedSessions(sessions)
+
+	if *fFormat != "" {
+		printFormatted(sessions, allSessions, *fFormat)
+		return
+	}
+	if *fJsonLines {
+		printSessionsJsonLines(allSessions, allColumns)
+		return
+	}
+	if *fJson {
+		printSessionsJson(allSessions, allColumns)
+		return
+	}
+	printSessionTable(allSessions, allColumns)
+}
+
+// printSessionTable выводит сессии таблицей
+func printSessionTable(allSessions []TmuxSession, allColumns bool) {
+	cols := []interface{}{"name", "path", "windows"}
+	if *fWindowNames {
+		cols = append(cols, "window names")
+	}
+	if *fGitColumn {
+		cols = append(cols, "branch")
+	}
+	if allColumns {
+		cols = append(cols, "created")
+	}
+	cols = append(cols, "activity", "attchd")
+	if allColumns {
+		cols = append(cols, "todo")
 	}
 
 	tbl := table.New(cols...)
 	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
-	columnFmt := color.New(color.FgYellow).SprintfFunc()
-	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	tbl.WithHeaderFormatter(headerFmt)
+	// в -interactive строки раскрашиваются по давности активности, иначе выделяем только имя
+	colorByAge := *fInteractive
+	if !colorByAge {
+		columnFmt := color.New(color.FgYellow).SprintfFunc()
+		tbl.WithFirstColumnFormatter(columnFmt)
+	}
+	// ширина по экранным колонкам (без учёта цветов), чтобы отметка вроде "●" не сбивала выравнивание
+	tbl.WithWidthFunc(func(s string) int {
+		return runewidth.StringWidth(ansiEscapeRe.ReplaceAllString(s, ""))
+	})
 
 	for _, s := range allSessions {
-		row := []interface{}{s.Name, s.Path, s.WindowsCount, s.FmtLastActivity(), s.FmtAttached()}
+		row := []interface{}{s.Name, truncatePath(s.Path, *fTruncate), s.WindowsCount}
+		if *fWindowNames {
+			row = append(row, strings.Join(windowNames(s), ","))
+		}
+		if *fGitColumn {
+			branch := ""
+			if s.Path != "" {
+				branch, _ = gitBranch(s.Path)
+			}
+			row = append(row, branch)
+		}
+		if allColumns {
+			row = append(row, s.FmtCreated())
+		}
+		row = append(row, s.FmtLastActivity(), s.FmtAttached())
 		if allColumns {
 			todo := getTodoContents(s.Path)
 			row = append(row, todo)
 		}
+		if colorByAge {
+			c := activityColor(s.LastActivity)
+			for i := range row {
+				row[i] = c.Sprint(row[i])
+			}
+		}
 		tbl.AddRow(row...)
 	}
 	tbl.Print()
 }
 
+// ansiEscapeRe это управляющая последовательность цвета терминала
+var ansiEscapeRe = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// activityColor возвращает цвет строки сессии по давности её активности:
+// зелёный - меньше часа, жёлтый - меньше суток, серый - давно или неизвестно
+func activityColor(lastActivity time.Time) *color.Color {
+	age := time.Since(lastActivity)
+	switch {
+	case lastActivity.IsZero():
+		return color.New(color.FgHiBlack)
+	case age < time.Hour:
+		return color.New(color.FgGreen)
+	case age < 24*time.Hour:
+		return color.New(color.FgYellow)
+	}
+	return color.New(color.FgHiBlack)
+}
+
 func main() {
 	flag.Parse()
 
+	if *fConfig != "" {
+		ConfigPath = *fConfig
+	}
+	configExists := isFile(ConfigPath)
+	Config.Load()
+
+	if *fFirstRun || (!configExists && flag.NFlag() == 0 && flag.NArg() == 0) {
+		firstRun()
+		if *fFirstRun {
+			return
+		}
+	}
+
+	// почти все команды работают с tmux (включая -todo, которому нужен каталог сессии):
+	// без него лучше сразу понятная ошибка, чем паника
+	if *fBatch != "" || needsTmux() {
+		if _, err := exec.LookPath("tmux"); err != nil {
+			fatalf("tmux not found in PATH")
+		}
+	}
+
+	if *fBatch != "" {
+		runBatch(*fBatch)
+		return
+	}
+	run()
+}
+
+// needsTmux возвращает false для команд, которые обходятся без tmux
+func needsTmux() bool {
+	return !(*fVersion || *fRecordDir != "" || *fWhichEditor || *fCompletion != "" || *fEditConfig)
+}
+
+// run выполняет действие, заданное флагами и аргументами командной строки (уже разобранными).
+// Конфиг к этому моменту загружен.
+func run() {
 	if *fVersion {
 		fmt.Printf("%s\n", VERSION)
 		return
 	}
 
+	if *fRecordDir != "" {
+		recordDir(*fRecordDir)
+		return
+	}
+
+	if *fWhichEditor {
+		printEditor()
+		return
+	}
+
+	if *fCompletion != "" {
+		printCompletionScript(*fCompletion)
+		return
+	}
+
+	if *fTodoAdd != "" {
+		appendTodo(*fTodoAdd)
+		return
+	}
+
 	if *fTodo {
 		openTodoEditor()
 		return
@@ -602,37 +1690,321 @@ func main() {
 		return
 	}
 
-	ss := listSessions()
+	ts := newTmuxState()
+	ss := ts.sessions
 
-	sessionId := ""
+	if *fComplete {
+		printCompletions(ss, *fCompleteDescriptions)
+		return
+	}
 
-	if *fTempProject {
-		sessionId = createTemporaryProject()
+	if *fFromGitBranch {
+		renameCurrentFromGitBranch(ss, *fKeepAlias, *fWindow)
+		Config.Save()
+		return
+	}
+
+	if *fRenameCurrentTo != "" {
+		renameCurrentSession(ss, *fRenameCurrentTo, *fKeepAlias)
+		Config.Save()
+		return
+	}
+
+	if *fRename != "" {
+		if flag.NArg() != 1 {
+			fatalf("usage: pr -rename <session> <new name>")
+		}
+		renameSessionById(ts, *fRename, flag.Arg(0))
+		Config.Save()
+		return
 	}
 
+	if *fRenameRegex != "" {
+		if flag.NArg() != 1 {
+			fatalf("usage: pr -rename-regex <regex> <replacement>")
+		}
+		renameByRegex(ss, *fRenameRegex, flag.Arg(0))
+		Config.Save()
+		return
+	}
+
+	sessionId := ""
+
 	args := flag.Args()
+	if *fTempProject {
+		// pr -T <имя>: аргумент это имя временного проекта, а не сессия для переключения
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+			args = args[1:]
+			if !isPlainName(name) {
+				fatalf("invalid temporary project name %s", name)
+			}
+		}
+		sessionId = createTemporaryProject(name)
+	}
 	if len(args) > 0 {
 		sessionId = args[0]
 	}
 
-	if *fInteractive {
-		printSessions(ss, *fWide)
-		fmt.Printf("input project name to switch to: ")
-		line := readLine()
-		if line == "" {
+	if *fBack {
+		if Config.LastAttached == "" {
+			fatalf("no previous session recorded yet")
+		}
+		sessionId = Config.LastAttached
+	}
+
+	if *fPrev || *fNext {
+		sessionId = historyStep(ts, *fNext)
+		// шаг по истории не должен её менять, иначе повторный -prev вернёт обратно
+		*fNoTouch = true
+	}
+
+	if *fAutoClean > 0 {
+		autoClean(ss, *fAutoClean)
+		return
+	}
+
+	if *fCloneSession != "" {
+		cloneSession(ts, *fCloneSession)
+		Config.Save()
+		return
+	}
+
+	if *fStatus {
+		printStatus(ts)
+		return
+	}
+
+	if *fSessionInfo != "" {
+		printSessionInfo(ts, *fSessionInfo, *fJson)
+		return
+	}
+
+	if *fSend != "" {
+		sendKeys(ts, *fSend, flag.Args())
+		Config.Save()
+		return
+	}
+
+	if *fCapture != "" {
+		captureSession(ts, *fCapture, *fOutput, *fAllWindows)
+		return
+	}
+
+	if *fShowCmd != "" {
+		printSessionCmd(*fShowCmd, *fJson)
+		return
+	}
+
+	if *fPinDisplay != "" {
+		Config.TogglePinned(*fPinDisplay)
+		Config.Save()
+		return
+	}
+
+	if *fKillWindow {
+		target := ""
+		if len(flag.Args()) > 0 {
+			target = flag.Arg(0)
+		}
+		killWindow(ss, target)
+		// при закрытии последнего окна pr переключается на предыдущую сессию: это меняет историю
+		Config.Save()
+		return
+	}
+
+	if *fDetach != "" {
+		detachSessionById(ts, *fDetach)
+		return
+	}
+
+	if *fKill != "" {
+		killSessionById(ts, *fKill)
+		Config.Save()
+		return
+	}
+
+	if *fKillOthers != "" {
+		killOthers(ts, *fKillOthers)
+		return
+	}
+
+	if *fSetRoot != "" {
+		if flag.NArg() != 1 {
+			fatalf("usage: pr -set-root <session> <path>")
+		}
+		if err := Config.SetRoot(*fSetRoot, flag.Arg(0)); err != nil {
+			fatal(err)
+		}
+		Config.Save()
+		return
+	}
+
+	if *fValidateLayout != "" {
+		printLayoutProblems(*fValidateLayout)
+		return
+	}
+
+	if *fMove != "" {
+		if flag.NArg() != 1 {
+			fatalf("usage: pr -move <session> <new path>")
+		}
+		moveProject(ts, *fMove, flag.Arg(0), *fGitMv)
+		Config.Save()
+		return
+	}
+
+	if *fImportTmuxinator != "" {
+		importTmuxinator(*fImportTmuxinator)
+		Config.Save()
+		return
+	}
+
+	if *fExportTmuxinator != "" {
+		exportTmuxinator(*fExportTmuxinator)
+		return
+	}
+
+	if *fTempClean {
+		cleanTemporaryProjects(ss, *fDryRun)
+		return
+	}
+
+	if *fPurgeTmpSessions {
+		purgeTemporarySessions(ss, *fForce)
+		return
+	}
+
+	if *fPruneEphemeral {
+		pruneEphemeralSessions()
+		Config.Save()
+		return
+	}
+
+	if *fTmpReport {
+		reportTemporaryProjects(ss, *fRenumber)
+		return
+	}
+
+	if *fTree {
+		printSessionTree(ss)
+		return
+	}
+
+	if *fAliasSuggest {
+		suggestAliases(*fApply)
+		Config.Save()
+		return
+	}
+
+	if *fAlias != "" {
+		if flag.NArg() != 2 {
+			fatalf("usage: pr -alias add|remove <session> <alias>")
+		}
+		manageAlias(ts, *fAlias, flag.Arg(0), flag.Arg(1))
+		Config.Save()
+		return
+	}
+
+	if *fEnv != "" {
+		if flag.NArg() != 2 {
+			fatalf("usage: pr -env set <session> KEY=VALUE or pr -env unset <session> KEY")
+		}
+		manageEnv(ts, *fEnv, flag.Arg(0), flag.Arg(1))
+		Config.Save()
+		return
+	}
+
+	if *fDedupeAliases {
+		dedupeAliases(*fApply)
+		Config.Save()
+		return
+	}
+
+	if *fPrune {
+		removed := Config.PruneMissing(*fDryRun)
+		for _, fs := range removed {
+			fmt.Printf("%s (%s)\n", fs.Name, fs.Path)
+		}
+		if *fDryRun {
+			fmt.Printf("would remove %d entries\n", len(removed))
+			return
+		}
+		fmt.Printf("removed %d entries\n", len(removed))
+		Config.Save()
+		return
+	}
+
+	if *fHistoryClear {
+		favourites := 0
+		for _, fs := range Config.Sessions {
+			if fs.IsFavourite() {
+				favourites++
+			}
+		}
+		history := len(Config.Sessions) - favourites
+		if history == 0 {
+			fmt.Printf("history is empty\n")
 			return
 		}
-		if line == "-T" {
-			sessionId = createTemporaryProject()
+		if confirm(fmt.Sprintf("remove %d history entries (keeping %d configured sessions)?", history, favourites)) {
+			Config.ClearHistory()
+			Config.Save()
+		}
+		return
+	}
+
+	if *fNew != "" {
+		newSession(ts, *fNew, *fAt, *fCmd, *fNoSwitch)
+		Config.Save()
+		return
+	}
+
+	if *fIn != "" {
+		runIn(ts, *fIn, flag.Args())
+		return
+	}
+
+	if *fSyncActivity {
+		syncActivity(ss)
+		Config.Save()
+		return
+	}
+
+	if *fTodoAll {
+		printAllTodos(ss)
+		return
+	}
+
+	if *fPathsOnly {
+		printPaths(ss)
+		return
+	}
+
+	if *fFzf {
+		if _, err := exec.LookPath("fzf"); err == nil {
+			sessionId = chooseWithFzf(ss)
+			if sessionId == "" {
+				return
+			}
 		} else {
-			sessionId = line
+			warn("fzf not found in PATH, falling back to -interactive")
+			*fInteractive = true
+		}
+	}
+
+	if *fInteractive {
+		sessionId = chooseInteractively(ts)
+		if sessionId == "" {
+			return
 		}
 	}
 
 	if sessionId != "" {
-		ChangeSession(ss, sessionId, *fAllowCreateDir)
+		ChangeSession(ts, sessionId, *fAllowCreateDir)
 		Config.Save()
 		return
 	}
-	printSessions(ss, *fWide)
+	printSessions(ts, *fWide)
 }
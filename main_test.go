@@ -0,0 +1,99 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTmuxEnvArgsSorted(t *testing.T) {
+	env := map[string]string{"PORT": "8080", "DEBUG": "a b=c"}
+	want := []string{"-e", "DEBUG=a b=c", "-e", "PORT=8080"}
+	// порядок обхода map случаен, поэтому проверяем несколько раз
+	for i := 0; i < 20; i++ {
+		args, err := tmuxEnvArgs(env)
+		if err != nil {
+			t.Fatalf("tmuxEnvArgs() error: %s", err)
+		}
+		if !reflect.DeepEqual(args, want) {
+			t.Fatalf("tmuxEnvArgs() = %q, want %q", args, want)
+		}
+	}
+}
+
+func TestTmuxEnvArgsEmpty(t *testing.T) {
+	args, err := tmuxEnvArgs(nil)
+	if err != nil || len(args) != 0 {
+		t.Errorf("tmuxEnvArgs(nil) = %q, %v, want no args", args, err)
+	}
+}
+
+func TestTmuxEnvArgsInvalidName(t *testing.T) {
+	for _, name := range []string{"", "1ABC", "A-B", "A B", "A=B"} {
+		if args, err := tmuxEnvArgs(map[string]string{"OK": "1", name: "x"}); err == nil {
+			t.Errorf("tmuxEnvArgs(%q) = %q, want error", name, args)
+		}
+	}
+}
+
+func TestResolveTargetAmbiguousPrefix(t *testing.T) {
+	withConfig(t, FavouritesConfig{})
+	withHome(t, t.TempDir())
+	ts := tmuxStateOf([]TmuxSession{
+		{Name: "foobar", Path: "/src/foobar"},
+		{Name: "foobaz", Path: "/src/foobaz"},
+	})
+	_, err := resolveTarget(ts, "foo", false)
+	if err == nil {
+		t.Fatal("resolveTarget(foo) succeeded, want ambiguity error")
+	}
+	if want := "foo is ambiguous, matching sessions: foobar, foobaz"; err.Error() != want {
+		t.Errorf("resolveTarget(foo) error = %q, want %q", err, want)
+	}
+
+	target, err := resolveTarget(ts, "foobaz", false)
+	if err != nil || target.Name != "foobaz" || target.Path != "/src/foobaz" {
+		t.Errorf("resolveTarget(foobaz) = %+v, %v, want foobaz in /src/foobaz", target, err)
+	}
+	target, err = resolveTarget(ts, "foobar", false)
+	if err != nil || target.Name != "foobar" {
+		t.Errorf("resolveTarget(foobar) = %+v, %v, want foobar", target, err)
+	}
+}
+
+// sessionRecord собирает запись о сессии в формате вывода tmux list-sessions из listSessions
+func sessionRecord(fields ...string) string {
+	return strings.Join(fields, tmuxFieldSep) + tmuxRecordSep + "\n"
+}
+
+func TestParseSessionsTabAndNewlineInPath(t *testing.T) {
+	out := sessionRecord("odd", "/tmp/a\tb\nc", "1", "3", "1700000100", "1700000000") +
+		sessionRecord("plain", "/src/plain", "0", "1", "1700000200", "1700000050")
+	want := []TmuxSession{
+		{
+			Name:         "odd",
+			Path:         "/tmp/a\tb\nc",
+			Attached:     true,
+			WindowsCount: 3,
+			LastActivity: time.Unix(1700000100, 0),
+			Created:      time.Unix(1700000000, 0),
+		},
+		{
+			Name:         "plain",
+			Path:         "/src/plain",
+			WindowsCount: 1,
+			LastActivity: time.Unix(1700000200, 0),
+			Created:      time.Unix(1700000050, 0),
+		},
+	}
+	if got := parseSessions(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSessions() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseSessionsEmpty(t *testing.T) {
+	if got := parseSessions(""); len(got) != 0 {
+		t.Errorf("parseSessions(\"\") = %+v, want no sessions", got)
+	}
+}
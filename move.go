@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// moveProject переносит каталог проекта sessionId в newPath и обновляет пути сессий в конфиге.
+// С useGit каталог, отслеживаемый git, переносится через git mv, иначе через os.Rename.
+func moveProject(ts *tmuxState, sessionId string, newPath string, useGit bool) {
+	target, err := resolveTarget(ts, sessionId, false)
+	if err != nil {
+		fatal(err)
+	}
+	oldPath := normalizePath(target.Path)
+	newPath, err = filepath.Abs(expandHome(newPath))
+	dieIfError(err)
+	if _, err := os.Stat(newPath); err == nil {
+		fatalf("cannot move %s to %s: destination already exists", oldPath, newPath)
+	}
+	if !isDir(filepath.Dir(newPath)) {
+		fatalf("cannot move %s to %s: directory %s does not exist", oldPath, newPath, filepath.Dir(newPath))
+	}
+
+	if useGit && gitIsTracked(oldPath) {
+		out, err := exec.Command("git", "-C", filepath.Dir(oldPath), "mv", oldPath, newPath).CombinedOutput()
+		if err != nil {
+			fatalf("git mv %s %s: %s: %s", oldPath, newPath, err, strings.TrimSpace(string(out)))
+		}
+	} else if err := os.Rename(oldPath, newPath); err != nil {
+		fatal(err)
+	}
+	info("moved %s to %s", oldPath, newPath)
+
+	for i := range Config.Sessions {
+		fs := &Config.Sessions[i]
+		path := normalizePath(fs.Path)
+		if path == oldPath || strings.HasPrefix(path, oldPath+string(filepath.Separator)) {
+			fs.Path = newPath + strings.TrimPrefix(path, oldPath)
+			Config.changed = true
+		}
+	}
+	for _, s := range ts.sessions {
+		if s.Path == oldPath {
+			warn("session %s is still open in %s, new windows will start in the old path", s.Name, oldPath)
+		}
+	}
+}
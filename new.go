@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// newSessionPlan это то, что сделает pr -new: какую сессию и где создать и переключаться ли на неё
+type newSessionPlan struct {
+	Name      string
+	Dir       string
+	Cmd       string
+	CreateDir bool // каталог Dir ещё не существует и должен быть создан (-c)
+	Switch    bool
+}
+
+// planNewSession разбирает сочетание -new, -at, -cmd, -no-switch и -c в план создания сессии.
+// Каталог по умолчанию это cwd; относительный каталог считается от cwd.
+func planNewSession(byName map[string]TmuxSession, name string, dir string, cwd string, startCmd string, noSwitch bool, allowCreateDir bool) (newSessionPlan, error) {
+	if _, ok := byName[name]; ok {
+		return newSessionPlan{}, fmt.Errorf("session %s already exists", name)
+	}
+	dir = expandPath(dir)
+	if dir == "" {
+		dir = cwd
+	} else if !filepath.IsAbs(dir) {
+		dir = filepath.Join(cwd, dir)
+	}
+	plan := newSessionPlan{
+		Name:   name,
+		Dir:    filepath.Clean(dir),
+		Cmd:    startCmd,
+		Switch: !noSwitch,
+	}
+	if !isDir(plan.Dir) {
+		if !allowCreateDir {
+			return newSessionPlan{}, fmt.Errorf("cannot create session in %s (directory does not exist): use -c flag to create a new directory", plan.Dir)
+		}
+		plan.CreateDir = true
+	}
+	return plan, nil
+}
+
+// newSession создаёт сессию с явно заданными именем, каталогом (по умолчанию текущим) и стартовой командой.
+// Если noSwitch, сессия создаётся в фоне.
+func newSession(ts *tmuxState, name string, dir string, startCmd string, noSwitch bool) {
+	cwd, err := os.Getwd()
+	dieIfError(err)
+	plan, err := planNewSession(ts.byName, name, dir, cwd, startCmd, noSwitch, *fAllowCreateDir)
+	if err != nil {
+		fatal(err)
+	}
+	if plan.CreateDir {
+		err := os.MkdirAll(plan.Dir, os.ModePerm)
+		dieIfError(err)
+	}
+
+	touchSession(plan.Name, plan.Dir)
+	createSession(plan.Name, plan.Dir, plan.Cmd, nil, nil, 0)
+	if !plan.Switch {
+		info("created session %s in %s", plan.Name, plan.Dir)
+		return
+	}
+	switchToSession(plan.Name)
+}
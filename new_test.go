@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlanNewSession(t *testing.T) {
+	cwd := t.TempDir()
+	withHome(t, cwd)
+	if err := os.Mkdir(filepath.Join(cwd, "sub"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]TmuxSession{"taken": {Name: "taken", Path: cwd}}
+
+	cases := []struct {
+		name     string
+		at       string
+		cmd      string
+		noSwitch bool
+		want     newSessionPlan
+	}{
+		{name: "defaults", want: newSessionPlan{Name: "defaults", Dir: cwd, Switch: true}},
+		{name: "at", at: filepath.Join(cwd, "sub"), want: newSessionPlan{Name: "at", Dir: filepath.Join(cwd, "sub"), Switch: true}},
+		{name: "relative", at: "sub/", want: newSessionPlan{Name: "relative", Dir: filepath.Join(cwd, "sub"), Switch: true}},
+		{name: "home", at: "~/sub", want: newSessionPlan{Name: "home", Dir: filepath.Join(cwd, "sub"), Switch: true}},
+		{name: "cmd", cmd: "make run", want: newSessionPlan{Name: "cmd", Dir: cwd, Cmd: "make run", Switch: true}},
+		{name: "bg", at: "sub", cmd: "htop", noSwitch: true, want: newSessionPlan{Name: "bg", Dir: filepath.Join(cwd, "sub"), Cmd: "htop"}},
+	}
+	for _, c := range cases {
+		plan, err := planNewSession(byName, c.name, c.at, cwd, c.cmd, c.noSwitch, false)
+		if err != nil {
+			t.Errorf("%s: planNewSession() error: %s", c.name, err)
+			continue
+		}
+		if plan != c.want {
+			t.Errorf("%s: planNewSession() = %+v, want %+v", c.name, plan, c.want)
+		}
+	}
+}
+
+func TestPlanNewSessionMissingDir(t *testing.T) {
+	cwd := t.TempDir()
+	missing := filepath.Join(cwd, "missing")
+	if _, err := planNewSession(nil, "x", missing, cwd, "", false, false); err == nil {
+		t.Error("planNewSession(missing dir) succeeded, want error without -c")
+	}
+	plan, err := planNewSession(nil, "x", missing, cwd, "", false, true)
+	if err != nil || !plan.CreateDir || plan.Dir != missing {
+		t.Errorf("planNewSession(missing dir, -c) = %+v, %v, want CreateDir in %s", plan, err, missing)
+	}
+}
+
+func TestPlanNewSessionNameTaken(t *testing.T) {
+	byName := map[string]TmuxSession{"api": {Name: "api"}}
+	if _, err := planNewSession(byName, "api", "", t.TempDir(), "", true, false); err == nil {
+		t.Error("planNewSession(existing name) succeeded, want error")
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// withHome подменяет домашний каталог (Home и $HOME) на время теста
+func withHome(t *testing.T, home string) {
+	t.Helper()
+	old := Home
+	Home = home
+	t.Setenv("HOME", home)
+	t.Cleanup(func() { Home = old })
+}
+
+// withConfig подменяет глобальный конфиг на время теста
+func withConfig(t *testing.T, c FavouritesConfig) {
+	t.Helper()
+	old := Config
+	Config = c
+	t.Cleanup(func() { Config = old })
+}
+
+func TestExpandPath(t *testing.T) {
+	withHome(t, "/home/user")
+	cases := map[string]string{
+		"~":             "/home/user",
+		"~/sub":         "/home/user/sub",
+		"$HOME/sub":     "/home/user/sub",
+		"${HOME}/sub":   "/home/user/sub",
+		"/abs/path":     "/abs/path",
+		"relative/path": "relative/path",
+		"~other/sub":    "~other/sub",
+	}
+	for in, want := range cases {
+		if got := expandPath(in); got != want {
+			t.Errorf("expandPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	withHome(t, "/home/user")
+	cases := map[string]string{
+		"":                "",
+		"~":               "/home/user",
+		"~/":              "/home/user",
+		"~/sub/":          "/home/user/sub",
+		"$HOME/sub/../x/": "/home/user/x",
+		"/abs//path/":     "/abs/path",
+	}
+	for in, want := range cases {
+		if got := normalizePath(in); got != want {
+			t.Errorf("normalizePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFindSavedSessionExpandsConfigPath(t *testing.T) {
+	withHome(t, "/home/user")
+	withConfig(t, FavouritesConfig{Sessions: []FavouriteSession{
+		{Name: "home", Path: "~"},
+		{Name: "api", Path: "~/work/api"},
+		{Name: "web", Path: "$HOME/work/web/"},
+	}})
+	cases := map[string]string{
+		"/home/user":          "home",
+		"/home/user/work/api": "api",
+		"/home/user/work/web": "web",
+	}
+	for path, want := range cases {
+		fs := findSavedSession(TmuxSession{Name: "other", Path: path})
+		if fs == nil {
+			t.Errorf("findSavedSession(%q) = nil, want %q", path, want)
+			continue
+		}
+		if fs.Name != want {
+			t.Errorf("findSavedSession(%q) = %q, want %q", path, fs.Name, want)
+		}
+	}
+	if fs := findSavedSession(TmuxSession{Name: "other", Path: "/home/user/work"}); fs != nil {
+		t.Errorf("findSavedSession(/home/user/work) = %q, want nil", fs.Name)
+	}
+}
+
+func TestAddRoot(t *testing.T) {
+	withHome(t, "/home/user")
+
+	fc := FavouritesConfig{}
+	if fc.AddRoot("/home/user/") || fc.changed {
+		t.Error("AddRoot(home) with empty roots = true, want false: home is already searched")
+	}
+	if !fc.AddRoot("/home/user/work") {
+		t.Error("AddRoot(~/work) = false, want true")
+	}
+	// домашняя директория остаётся в поиске после добавления первого каталога
+	if want := []string{"~", "/home/user/work"}; !reflect.DeepEqual(fc.Roots, want) {
+		t.Errorf("Roots = %q, want %q", fc.Roots, want)
+	}
+	if !fc.changed {
+		t.Error("AddRoot did not mark config as changed")
+	}
+	if fc.AddRoot("/home/user/work/") || fc.AddRoot("/home/user") {
+		t.Error("AddRoot(existing root) = true, want false")
+	}
+
+	// явно заданные roots без домашней директории: её можно вернуть
+	fc = FavouritesConfig{Roots: []string{"~/src"}}
+	if !fc.AddRoot("$HOME") {
+		t.Error("AddRoot($HOME) = false, want true: home is not in roots")
+	}
+	if want := []string{"~/src", "~"}; !reflect.DeepEqual(fc.Roots, want) {
+		t.Errorf("Roots = %q, want %q", fc.Roots, want)
+	}
+	if got := fc.ProjectRoots(); !reflect.DeepEqual(got, []string{"/home/user/src", "/home/user"}) {
+		t.Errorf("ProjectRoots() = %q, want [/home/user/src /home/user]", got)
+	}
+}
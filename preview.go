@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+)
+
+// previewCache хранит уже построенные превью сессий по каталогу, чтобы не запускать git
+// повторно в рамках одного запуска -interactive
+type previewCache map[string]string
+
+// preview возвращает превью сессии для -interactive: содержимое .todo и/или состояние git,
+// в зависимости от interactive_preview в конфиге (todo, git или both; по умолчанию todo)
+func (pc previewCache) preview(s TmuxSession) string {
+	if p, ok := pc[s.Path]; ok {
+		return p
+	}
+	mode := Config.InteractivePreview
+	if mode == "" {
+		mode = "todo"
+	}
+	parts := []string{}
+	if mode == "git" || mode == "both" {
+		if g := gitSummary(s.Path); g != "" {
+			parts = append(parts, g)
+		}
+	}
+	if mode == "todo" || mode == "both" {
+		if todo := strings.TrimSpace(getTodoContents(s.Path)); todo != "" {
+			parts = append(parts, todo)
+		}
+	}
+	p := s.Name + ": " + s.Path
+	if len(parts) > 0 {
+		p += "\n" + strings.Join(parts, "\n")
+	}
+	pc[s.Path] = p
+	return p
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"path/filepath"
+)
+
+// ProjectType это команда и окружение по умолчанию для проектов определённого типа
+type ProjectType struct {
+	Cmd string            `json:"cmd,omitempty" yaml:"cmd,omitempty"`
+	Env map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+}
+
+// projectMarkers это файлы, по которым определяется тип проекта (в порядке проверки)
+var projectMarkers = []struct {
+	Type string
+	File string
+}{
+	{"go", "go.mod"},
+	{"node", "package.json"},
+	{"rust", "Cargo.toml"},
+}
+
+// detectProjectType возвращает тип проекта в каталоге dir или пустую строку, если тип не определён
+func detectProjectType(dir string) string {
+	for _, m := range projectMarkers {
+		if isFile(filepath.Join(dir, m.File)) {
+			return m.Type
+		}
+	}
+	return ""
+}
+
+// withProjectTypeDefaults дополняет сессию командой и окружением из project_types,
+// если у самой сессии они не заданы
+func withProjectTypeDefaults(target sessionTarget) sessionTarget {
+	pt, ok := Config.ProjectTypes[detectProjectType(target.Path)]
+	if !ok {
+		return target
+	}
+	if target.StartCmd == "" {
+		target.StartCmd = pt.Cmd
+	}
+	if len(target.Env) == 0 {
+		target.Env = pt.Env
+	}
+	return target
+}
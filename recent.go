@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxRecentDirs это сколько недавних каталогов хранить в файле
+const maxRecentDirs = 100
+
+// recentDirsFilename возвращает путь к файлу с недавними каталогами
+func recentDirsFilename() string {
+	if Config.RecentDirsFile == "" {
+		return filepath.Join(Home, ".local", "share", "pr", "recent_dirs")
+	}
+	return expandPath(Config.RecentDirsFile)
+}
+
+// readRecentDirs возвращает существующие недавние каталоги, начиная с самого свежего
+func readRecentDirs() []string {
+	bs, err := os.ReadFile(recentDirsFilename())
+	if err != nil {
+		return []string{}
+	}
+	lines := strings.Split(strings.TrimSpace(string(bs)), "\n")
+	dirs := []string{}
+	for i := len(lines) - 1; i >= 0; i-- {
+		if lines[i] != "" && isDir(lines[i]) {
+			dirs = append(dirs, lines[i])
+		}
+	}
+	return dirs
+}
+
+// recordDir дописывает каталог в конец списка недавних каталогов (убирая его прежнее вхождение)
+func recordDir(dir string) {
+	dir, err := filepath.Abs(dir)
+	dieIfError(err)
+	if dir == Home || !isDir(dir) {
+		return
+	}
+
+	fname := recentDirsFilename()
+	lines := []string{}
+	if bs, err := os.ReadFile(fname); err == nil {
+		for _, line := range strings.Split(strings.TrimSpace(string(bs)), "\n") {
+			if line != "" && line != dir {
+				lines = append(lines, line)
+			}
+		}
+	}
+	lines = append(lines, dir)
+	if len(lines) > maxRecentDirs {
+		lines = lines[len(lines)-maxRecentDirs:]
+	}
+
+	err = os.MkdirAll(filepath.Dir(fname), 0750)
+	dieIfError(err)
+	err = os.WriteFile(fname, []byte(strings.Join(lines, "\n")+"\n"), 0640)
+	dieIfError(err)
+}
+
+// findRecentDir ищет недавний каталог по точному совпадению имени, затем по префиксу
+func findRecentDir(sessionId string) string {
+	dirs := readRecentDirs()
+	for _, d := range dirs {
+		if filepath.Base(d) == sessionId {
+			return d
+		}
+	}
+	for _, d := range dirs {
+		if strings.HasPrefix(filepath.Base(d), sessionId) {
+			return d
+		}
+	}
+	return ""
+}
+
+// withRecentDirs дополняет список сессий недавними каталогами, для которых ещё нет сессии
+func withRecentDirs(sessions []TmuxSession) []TmuxSession {
+	knownPaths := make(map[string]bool)
+	for _, s := range sessions {
+		knownPaths[normalizePath(s.Path)] = true
+	}
+	for _, fs := range Config.Sessions {
+		knownPaths[normalizePath(fs.Path)] = true
+	}
+	result := append([]TmuxSession{}, sessions...)
+	for _, d := range readRecentDirs() {
+		if !knownPaths[normalizePath(d)] {
+			result = append(result, TmuxSession{Name: filepath.Base(d), Path: d})
+		}
+	}
+	return result
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// freeSessionName подбирает для сессии имя с числовым суффиксом (name, name1, name2, ...),
+// не занятое ни одной из сессий sessionsByName
+func freeSessionName(sessionsByName map[string]TmuxSession, name string) string {
+	for i := 0; ; i++ {
+		if _, ok := sessionsByName[suffixedName(name, i)]; !ok {
+			return suffixedName(name, i)
+		}
+	}
+}
+
+// renameSession переименовывает живую сессию tmux
+func renameSession(oldName string, newName string) {
+	out, err := exec.Command("tmux", "rename-session", "-t", oldName, newName).CombinedOutput()
+	if err != nil {
+		log.Printf("failed: %s", string(out))
+		dieIfError(err)
+	}
+}
+
+// freeRenameName подбирает новое имя для сессии oldName: newName или newName с суффиксом,
+// если имя занято другой живой сессией или другой сессией из конфига
+func freeRenameName(sessions []TmuxSession, saved []FavouriteSession, oldName string, newName string) string {
+	taken := make(map[string]TmuxSession)
+	for _, s := range sessions {
+		if s.Name != oldName {
+			taken[s.Name] = s
+		}
+	}
+	for _, fs := range saved {
+		if fs.Name != oldName {
+			taken[fs.Name] = fs.TmuxSession()
+		}
+	}
+	return freeSessionName(taken, newName)
+}
+
+// renameCurrentSession переименовывает текущую сессию в newName (с суффиксом, если имя занято
+// живой сессией или сессией из конфига) и переименовывает её запись в конфиге.
+// Если keepAlias, старое имя остаётся алиасом сессии (кроме временных сессий, которых нет в конфиге).
+func renameCurrentSession(sessions []TmuxSession, newName string, keepAlias bool) {
+	if os.Getenv("TMUX") == "" {
+		fatalf("cannot rename current session: not inside tmux")
+	}
+	oldName := getCurrentSessionName()
+	if oldName == newName {
+		return
+	}
+
+	name := freeRenameName(sessions, Config.Sessions, oldName, newName)
+
+	renameSession(oldName, name)
+	dieIfError(Config.Rename(oldName, name))
+	if keepAlias {
+		if Config.Find(name) == nil {
+			path := getSessionPath()
+			if isEphemeralPath(path) {
+				// запись для временной сессии не сохранится в конфиге, алиасу негде жить
+				warn("not keeping %s as an alias: temporary sessions are not saved in the config", oldName)
+			} else {
+				Config.Touch(name, path)
+			}
+		}
+		Config.AddAlias(name, oldName)
+	}
+	info("renamed %s to %s", oldName, name)
+}
+
+// renameByRegex переименовывает живые сессии и сессии из конфига, имена которых подходят
+// под регулярное выражение pattern, заменяя совпадение на replacement (допускаются $1, ${name}).
+// Сначала печатает план переименований и спрашивает подтверждение. Если новое имя занято,
+// к нему добавляется числовой суффикс.
+func renameByRegex(sessions []TmuxSession, pattern string, replacement string) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		fatalf("invalid regex %s: %s", pattern, err)
+	}
+	plan, err := planRegexRenames(re, replacement, sessions, Config.Sessions)
+	if err != nil {
+		fatal(err)
+	}
+	live := make(map[string]bool)
+	for _, s := range sessions {
+		live[s.Name] = true
+	}
+
+	if len(plan) == 0 {
+		info("no sessions match %s", pattern)
+		return
+	}
+	for _, r := range plan {
+		fmt.Printf("%s -> %s\n", r.from, r.to)
+	}
+	if !confirm(fmt.Sprintf("rename %d sessions?", len(plan))) {
+		return
+	}
+	for _, r := range plan {
+		if live[r.from] {
+			renameSession(r.from, r.to)
+		}
+		dieIfError(Config.Rename(r.from, r.to))
+	}
+}
+
+// renaming это одно переименование из плана renameByRegex
+type renaming struct{ from, to string }
+
+// planRegexRenames составляет план переименований для renameByRegex: живые сессии, затем
+// сессии из конфига, имена которых подходят под re. Занятое новое имя получает числовой
+// суффикс; имена, выбранные раньше в том же плане, тоже считаются занятыми.
+func planRegexRenames(re *regexp.Regexp, replacement string, sessions []TmuxSession, saved []FavouriteSession) ([]renaming, error) {
+	taken := make(map[string]TmuxSession)
+	names := []string{}
+	for _, s := range sessions {
+		taken[s.Name] = s
+		names = append(names, s.Name)
+	}
+	for _, fs := range saved {
+		if _, ok := taken[fs.Name]; !ok {
+			taken[fs.Name] = fs.TmuxSession()
+			names = append(names, fs.Name)
+		}
+	}
+
+	plan := []renaming{}
+	for _, name := range names {
+		if !re.MatchString(name) {
+			continue
+		}
+		newName := re.ReplaceAllString(name, replacement)
+		if newName == name {
+			continue
+		}
+		if newName == "" {
+			return nil, fmt.Errorf("cannot rename %s: new name is empty", name)
+		}
+		to := freeSessionName(taken, newName)
+		taken[to] = TmuxSession{Name: to}
+		plan = append(plan, renaming{name, to})
+	}
+	return plan, nil
+}
+
+// checkNameFree проверяет, что сессию oldName можно переименовать в newName:
+// имя не занято ни живой сессией, ни другой сессией из конфига
+func checkNameFree(ts *tmuxState, oldName string, newName string) error {
+	if _, ok := ts.byName[newName]; ok {
+		return fmt.Errorf("cannot rename %s to %s: session %s already exists", oldName, newName, newName)
+	}
+	if oldName != newName && Config.Find(newName) != nil {
+		return fmt.Errorf("cannot rename %s to %s: saved session %s already exists", oldName, newName, newName)
+	}
+	return nil
+}
+
+// renameSessionById переименовывает живую сессию, найденную так же, как при переключении,
+// и её запись в конфиге (алиасы, команда и окружение сохраняются)
+func renameSessionById(ts *tmuxState, id string, newName string) {
+	target, err := resolveTarget(ts, id, false)
+	if err != nil {
+		fatal(err)
+	}
+	if err := checkNameFree(ts, target.Name, newName); err != nil {
+		fatal(err)
+	}
+	if _, live := ts.byName[target.Name]; !live {
+		fatalf("cannot rename %s: session is not running", target.Name)
+	}
+	renameSession(target.Name, newName)
+	dieIfError(Config.Rename(target.Name, newName))
+	info("renamed %s to %s", target.Name, newName)
+}
+
+// sanitizeSessionName заменяет символы, недопустимые в именах сессий и окон tmux
+func sanitizeSessionName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == ':' || unicode.IsSpace(r) {
+			return '-'
+		}
+		return r
+	}, name)
+}
+
+// renameCurrentFromGitBranch переименовывает текущую сессию (или текущее окно, если window)
+// в <каталог>/<ветка git>, где каталог - последний элемент пути текущего каталога
+func renameCurrentFromGitBranch(sessions []TmuxSession, keepAlias bool, window bool) {
+	if os.Getenv("TMUX") == "" {
+		fatalf("cannot rename: not inside tmux")
+	}
+	cwd, err := os.Getwd()
+	dieIfError(err)
+	branch, ok := gitBranch(cwd)
+	if !ok {
+		fatalf("cannot rename: %s is not inside a git repository", cwd)
+	}
+	name := sanitizeSessionName(filepath.Base(cwd) + "/" + branch)
+	if !window {
+		renameCurrentSession(sessions, name, keepAlias)
+		return
+	}
+	out, err := exec.Command("tmux", "rename-window", name).CombinedOutput()
+	if err != nil {
+		fatalf("cannot rename window: %s: %s", err, strings.TrimSpace(string(out)))
+	}
+	info("renamed window to %s", name)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestPlanRegexRenamesSubstitution(t *testing.T) {
+	sessions := []TmuxSession{{Name: "old-api"}, {Name: "web"}}
+	saved := []FavouriteSession{{Name: "old-db"}, {Name: "old-api"}}
+	plan, err := planRegexRenames(regexp.MustCompile(`^old-(\w+)$`), "new-$1", sessions, saved)
+	if err != nil {
+		t.Fatalf("planRegexRenames() error: %s", err)
+	}
+	want := []renaming{{"old-api", "new-api"}, {"old-db", "new-db"}}
+	if !reflect.DeepEqual(plan, want) {
+		t.Errorf("planRegexRenames() = %v, want %v", plan, want)
+	}
+}
+
+func TestPlanRegexRenamesCollisions(t *testing.T) {
+	// api занято живой сессией, api1 - сессией из конфига; x-api и y-api
+	// переименовываются в одно и то же имя и не должны столкнуться между собой
+	sessions := []TmuxSession{{Name: "api"}, {Name: "x-api"}}
+	saved := []FavouriteSession{{Name: "api1"}, {Name: "y-api"}}
+	plan, err := planRegexRenames(regexp.MustCompile(`^[xy]-`), "", sessions, saved)
+	if err != nil {
+		t.Fatalf("planRegexRenames() error: %s", err)
+	}
+	want := []renaming{{"x-api", "api2"}, {"y-api", "api3"}}
+	if !reflect.DeepEqual(plan, want) {
+		t.Errorf("planRegexRenames() = %v, want %v", plan, want)
+	}
+}
+
+func TestPlanRegexRenamesEmptyName(t *testing.T) {
+	sessions := []TmuxSession{{Name: "tmp"}}
+	if plan, err := planRegexRenames(regexp.MustCompile(`.*`), "", sessions, nil); err == nil {
+		t.Errorf("planRegexRenames() = %v, want error for empty name", plan)
+	}
+}
+
+func TestPlanRegexRenamesNoMatch(t *testing.T) {
+	sessions := []TmuxSession{{Name: "api"}}
+	plan, err := planRegexRenames(regexp.MustCompile(`^web`), "site", sessions, nil)
+	if err != nil || len(plan) != 0 {
+		t.Errorf("planRegexRenames() = %v, %v, want empty plan", plan, err)
+	}
+}
+
+func TestCheckNameFree(t *testing.T) {
+	withConfig(t, FavouritesConfig{Sessions: []FavouriteSession{{Name: "api"}, {Name: "web"}}})
+	ts := tmuxStateOf([]TmuxSession{{Name: "api"}, {Name: "db"}})
+
+	if err := checkNameFree(ts, "api", "db"); err == nil {
+		t.Error("checkNameFree(api, db) succeeded, want error: db is a live session")
+	}
+	if err := checkNameFree(ts, "api", "web"); err == nil {
+		t.Error("checkNameFree(api, web) succeeded, want error: web is a saved session")
+	}
+	if err := checkNameFree(ts, "api", "shop"); err != nil {
+		t.Errorf("checkNameFree(api, shop) error: %s", err)
+	}
+}
+
+func TestConfigRenameRefusesDuplicate(t *testing.T) {
+	fc := FavouritesConfig{
+		Sessions: []FavouriteSession{{Name: "api", Path: "/src/api"}, {Name: "web", Path: "/src/web"}},
+		History:  []string{"api", "web"},
+	}
+	if err := fc.Rename("api", "web"); err == nil {
+		t.Fatal("Rename(api, web) succeeded, want error: web is already saved")
+	}
+	if fc.Sessions[0].Name != "api" || fc.History[0] != "api" || fc.changed {
+		t.Errorf("failed Rename changed the config: %+v", fc)
+	}
+
+	if err := fc.Rename("api", "shop"); err != nil {
+		t.Fatalf("Rename(api, shop) error: %s", err)
+	}
+	if fc.Find("shop") == nil || fc.Find("api") != nil || fc.History[0] != "shop" {
+		t.Errorf("Rename(api, shop) = %+v, want api renamed to shop", fc)
+	}
+}
+
+func TestFreeRenameName(t *testing.T) {
+	sessions := []TmuxSession{{Name: "cur"}, {Name: "api"}}
+	saved := []FavouriteSession{{Name: "cur"}, {Name: "api1"}, {Name: "web"}}
+	cases := map[string]string{
+		"api":  "api2", // api живая, api1 в конфиге
+		"web":  "web1", // web только в конфиге
+		"shop": "shop",
+		"cur1": "cur1",
+	}
+	for newName, want := range cases {
+		if got := freeRenameName(sessions, saved, "cur", newName); got != want {
+			t.Errorf("freeRenameName(cur, %s) = %q, want %q", newName, got, want)
+		}
+	}
+	// собственное имя сессии не считается занятым
+	if got := freeRenameName(sessions, saved, "api1", "api1"); got != "api1" {
+		t.Errorf("freeRenameName(api1, api1) = %q, want api1", got)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// runIn находит каталог проекта так же, как при переключении сессии, и запускает в нём
+// команду args вне tmux. Завершает pr с кодом возврата команды.
+func runIn(ts *tmuxState, sessionId string, args []string) {
+	if len(args) == 0 {
+		fatalf("usage: pr -in <name> -- <cmd...>")
+	}
+	target, err := resolveTarget(ts, sessionId, false)
+	if err != nil {
+		fatal(err)
+	}
+	if !isDir(target.Path) {
+		fatalf("cannot run in %s: directory does not exist", target.Path)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = target.Path
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if inBatch {
+			fatalf("command exited with code %d", exitErr.ExitCode())
+		}
+		os.Exit(exitErr.ExitCode())
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
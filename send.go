@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// sendKeys передаёт клавиши keys в активную панель сессии через tmux send-keys
+// (в том числе специальные: Enter, C-c и т.п.). Если сессии ещё нет, она создаётся в фоне.
+func sendKeys(ts *tmuxState, sessionId string, keys []string) {
+	if len(keys) == 0 {
+		fatalf("usage: pr -send <session> <keys...>")
+	}
+	target, err := resolveTarget(ts, sessionId, *fAllowCreateDir)
+	if err != nil {
+		fatal(err)
+	}
+	if _, live := ts.byName[target.Name]; !live {
+		if target.CreateDir {
+			err := os.MkdirAll(target.Path, os.ModePerm)
+			dieIfError(err)
+		}
+		touchSession(target.Name, target.Path)
+		createSession(target.Name, target.Path, target.StartCmd, target.Env, target.Layout, target.DefaultWindow)
+		info("created session %s in %s", target.Name, target.Path)
+	}
+
+	args := append([]string{"send-keys", "-t", target.Name}, keys...)
+	out, err := exec.Command("tmux", args...).CombinedOutput()
+	logCommand(target.Name, args, err, out)
+	if err != nil {
+		fatalf("cannot send keys to %s: %s: %s", target.Name, err, strings.TrimSpace(string(out)))
+	}
+}
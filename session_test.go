@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSuffixedName(t *testing.T) {
+	cases := map[int]string{0: "api", 1: "api1", 9: "api9", 10: "api10", 123: "api123"}
+	for i, want := range cases {
+		if got := suffixedName("api", i); got != want {
+			t.Errorf("suffixedName(api, %d) = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestChooseSessionNameManyCollisions(t *testing.T) {
+	byName := map[string]TmuxSession{}
+	for i := 0; i < 12; i++ {
+		name := suffixedName("api", i)
+		byName[name] = TmuxSession{Name: name, Path: fmt.Sprintf("/src/%d/api", i)}
+	}
+
+	name, exists := chooseSessionName(byName, "api", "/src/new/api")
+	if name != "api12" || exists {
+		t.Errorf("chooseSessionName(new path) = %q, %t, want api12, false", name, exists)
+	}
+
+	// сессия в том же каталоге переиспользуется, даже если её имя далеко за девятым суффиксом
+	name, exists = chooseSessionName(byName, "api", "/src/10/api")
+	if name != "api10" || !exists {
+		t.Errorf("chooseSessionName(existing path) = %q, %t, want api10, true", name, exists)
+	}
+}
+
+func TestChooseSessionNameNoCollision(t *testing.T) {
+	byName := map[string]TmuxSession{"web": {Name: "web", Path: "/src/web"}}
+	if name, exists := chooseSessionName(byName, "api", "/src/api"); name != "api" || exists {
+		t.Errorf("chooseSessionName() = %q, %t, want api, false", name, exists)
+	}
+	if name, exists := chooseSessionName(byName, "web", "/src/web"); name != "web" || !exists {
+		t.Errorf("chooseSessionName() = %q, %t, want web, true", name, exists)
+	}
+}
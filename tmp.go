@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// tmpRoot возвращает каталог временных проектов (tmp_root из конфига, по умолчанию /tmp)
+func tmpRoot() string {
+	if Config.TmpRoot == "" {
+		return "/tmp"
+	}
+	return filepath.Clean(expandPath(Config.TmpRoot))
+}
+
+// isTemporaryPath возвращает true, если path находится внутри каталога временных проектов
+func isTemporaryPath(path string) bool {
+	return strings.HasPrefix(path, tmpRoot()+string(filepath.Separator))
+}
+
+// isEphemeralPath возвращает true, если сессии в каталоге path не нужно сохранять в истории:
+// он находится в tmp_root или начинается с одного из ephemeral_prefixes
+func isEphemeralPath(path string) bool {
+	if isTemporaryPath(path) {
+		return true
+	}
+	for _, prefix := range Config.EphemeralPrefixes {
+		if prefix != "" && strings.HasPrefix(path, expandPath(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneEphemeralSessions удаляет из конфига сессии, которые по текущим tmp_root
+// и ephemeral_prefixes считаются временными (например, после добавления нового префикса)
+func pruneEphemeralSessions() {
+	kept := Config.Sessions[:0]
+	removed := 0
+	for _, fs := range Config.Sessions {
+		if isEphemeralPath(fs.ExpandedPath()) {
+			fmt.Printf("removed %s (%s)\n", fs.Name, fs.Path)
+			removed++
+			continue
+		}
+		kept = append(kept, fs)
+	}
+	Config.Sessions = kept
+	if removed == 0 {
+		info("no ephemeral sessions in config")
+		return
+	}
+	Config.changed = true
+}
+
+// temporaryProjectRe это имя каталога временного проекта, созданного pr -T
+var temporaryProjectRe = regexp.MustCompile(`^t(\d+)$`)
+
+// temporaryProject это каталог временного проекта и сессия, которая в нём открыта
+type temporaryProject struct {
+	Number  int
+	Path    string
+	Session string // пусто, если сессии нет
+}
+
+// listTemporaryProjects возвращает временные проекты tN в tmp_root, упорядоченные по номеру
+func listTemporaryProjects(sessions []TmuxSession) []temporaryProject {
+	entries, err := os.ReadDir(tmpRoot())
+	dieIfError(err)
+	sessionsByPath := make(map[string]string)
+	for _, s := range sessions {
+		sessionsByPath[s.Path] = s.Name
+	}
+	projects := []temporaryProject{}
+	for _, e := range entries {
+		m := temporaryProjectRe.FindStringSubmatch(e.Name())
+		if m == nil || !e.IsDir() {
+			continue
+		}
+		n, _ := strconv.Atoi(m[1])
+		p := filepath.Join(tmpRoot(), e.Name())
+		projects = append(projects, temporaryProject{Number: n, Path: p, Session: sessionsByPath[p]})
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].Number < projects[j].Number
+	})
+	return projects
+}
+
+// planRenumbering составляет план перенумерации временных проектов (упорядоченных по номеру)
+// в root так, чтобы номера шли подряд с 0. Проект с живой сессией перенести нельзя:
+// tmux не умеет менять каталог сессии. Такие проекты, которым нужен другой номер,
+// возвращаются в blocked, и тогда переносить ничего не нужно.
+func planRenumbering(projects []temporaryProject, root string) (moves [][2]string, blocked []temporaryProject) {
+	for i, p := range projects {
+		if p.Number == i {
+			continue
+		}
+		if p.Session != "" {
+			blocked = append(blocked, p)
+			continue
+		}
+		moves = append(moves, [2]string{p.Path, filepath.Join(root, fmt.Sprintf("t%d", i))})
+	}
+	if len(blocked) > 0 {
+		return nil, blocked
+	}
+	return moves, nil
+}
+
+// reportTemporaryProjects печатает временные проекты и их сессии.
+// Если renumber, переносит проекты на младшие номера, чтобы нумерация стала плотной.
+// Если для этого пришлось бы перенести проект с живой сессией, ничего не переносит и сообщает,
+// какие сессии нужно сначала завершить.
+func reportTemporaryProjects(sessions []TmuxSession, renumber bool) {
+	projects := listTemporaryProjects(sessions)
+	if len(projects) == 0 {
+		fmt.Printf("no temporary projects\n")
+		return
+	}
+	for _, p := range projects {
+		session := p.Session
+		if session == "" {
+			session = "-"
+		}
+		fmt.Printf("%-12s %s\n", p.Path, session)
+	}
+	if !renumber {
+		return
+	}
+
+	moves, blocked := planRenumbering(projects, tmpRoot())
+	if len(blocked) > 0 {
+		names := []string{}
+		for _, p := range blocked {
+			names = append(names, fmt.Sprintf("%s (%s)", p.Session, p.Path))
+		}
+		fatalf("cannot renumber: live sessions cannot change their directory, kill them first: %s", strings.Join(names, ", "))
+	}
+	if len(moves) == 0 {
+		fmt.Printf("numbering is already contiguous\n")
+		return
+	}
+	for _, m := range moves {
+		fmt.Printf("move %s -> %s\n", m[0], m[1])
+	}
+	if !confirm(fmt.Sprintf("renumber %d temporary project(s)?", len(moves))) {
+		return
+	}
+	for _, m := range moves {
+		if err := os.Rename(m[0], m[1]); err != nil {
+			log.Printf("cannot move %s: %s", m[0], err)
+		}
+	}
+}
+
+// cleanTemporaryProjects удаляет пустые каталоги временных проектов tN, в которых
+// (и во вложенных каталогах которых) нет живых сессий, и печатает, сколько удалено.
+// С dryRun только печатает, что было бы удалено.
+func cleanTemporaryProjects(sessions []TmuxSession, dryRun bool) {
+	removed := 0
+	for _, p := range listTemporaryProjects(sessions) {
+		inUse := false
+		for _, s := range sessions {
+			if s.Path == p.Path || strings.HasPrefix(s.Path, p.Path+string(filepath.Separator)) {
+				inUse = true
+				break
+			}
+		}
+		if inUse {
+			continue
+		}
+		entries, err := os.ReadDir(p.Path)
+		if err != nil || len(entries) > 0 {
+			continue
+		}
+		if dryRun {
+			fmt.Printf("would remove %s\n", p.Path)
+			removed++
+			continue
+		}
+		if err := os.Remove(p.Path); err != nil {
+			log.Printf("cannot remove %s: %s", p.Path, err)
+			continue
+		}
+		removed++
+	}
+	if dryRun {
+		fmt.Printf("would remove %d temporary project(s)\n", removed)
+		return
+	}
+	fmt.Printf("removed %d temporary project(s)\n", removed)
+}
+
+// temporaryProjectDir возвращает каталог проекта верхнего уровня внутри tmp_root, содержащий path
+func temporaryProjectDir(path string) string {
+	rel, err := filepath.Rel(tmpRoot(), path)
+	dieIfError(err)
+	return filepath.Join(tmpRoot(), strings.SplitN(rel, string(filepath.Separator), 2)[0])
+}
+
+// purgeTemporarySessions завершает все живые сессии в каталоге временных проектов и удаляет их
+// каталоги. Непустые каталоги удаляются только с force.
+func purgeTemporarySessions(sessions []TmuxSession, force bool) {
+	current := ""
+	if os.Getenv("TMUX") != "" {
+		current = getCurrentSessionName()
+	}
+	toKill := []TmuxSession{}
+	for _, s := range sessions {
+		if isTemporaryPath(s.Path) && s.Name != current {
+			toKill = append(toKill, s)
+		}
+	}
+	for _, s := range sessions {
+		// текущую сессию завершаем последней, иначе pr завершится вместе с ней
+		if isTemporaryPath(s.Path) && s.Name == current {
+			toKill = append(toKill, s)
+		}
+	}
+	if len(toKill) == 0 {
+		fmt.Printf("no temporary sessions\n")
+		return
+	}
+
+	dirs := []string{}
+	seen := make(map[string]bool)
+	for _, s := range toKill {
+		dir := temporaryProjectDir(s.Path)
+		fmt.Printf("kill %s (%s)\n", s.Name, dir)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	if !confirm(fmt.Sprintf("kill %d session(s) and remove their directories?", len(toKill))) {
+		return
+	}
+	// каталоги удаляем до сессий: если среди них текущая, pr завершится вместе с ней
+	for _, dir := range dirs {
+		var err error
+		if force {
+			err = os.RemoveAll(dir)
+		} else {
+			err = os.Remove(dir)
+		}
+		if err != nil {
+			log.Printf("cannot remove %s (use -force to remove non-empty directories): %s", dir, err)
+		}
+	}
+	for _, s := range toKill {
+		if err := killSession(s.Name); err != nil {
+			log.Printf("%s", err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPruneEphemeralSessions(t *testing.T) {
+	withHome(t, "/home/user")
+	withConfig(t, FavouritesConfig{
+		TmpRoot:           "~/tmp",
+		EphemeralPrefixes: []string{"/var/tmp/", "$HOME/scratch/"},
+		Sessions: []FavouriteSession{
+			{Name: "api", Path: "/src/api"},
+			{Name: "t1", Path: "~/tmp/t1"},
+			{Name: "vartmp", Path: "/var/tmp/x"},
+			{Name: "scratch", Path: "~/scratch/idea"},
+			{Name: "tmpish", Path: "/home/user/tmpfile"},
+			{Name: "web", Path: "~/work/web"},
+		},
+	})
+
+	pruneEphemeralSessions()
+
+	names := []string{}
+	for _, fs := range Config.Sessions {
+		names = append(names, fs.Name)
+	}
+	if len(names) != 3 || names[0] != "api" || names[1] != "tmpish" || names[2] != "web" {
+		t.Errorf("sessions after prune = %q, want [api tmpish web]", names)
+	}
+	if !Config.changed {
+		t.Error("pruneEphemeralSessions did not mark config as changed")
+	}
+}
+
+func TestPruneEphemeralSessionsNothingToRemove(t *testing.T) {
+	withHome(t, "/home/user")
+	withConfig(t, FavouritesConfig{
+		EphemeralPrefixes: []string{"/var/tmp/"},
+		Sessions:          []FavouriteSession{{Name: "api", Path: "/src/api"}},
+	})
+	pruneEphemeralSessions()
+	if len(Config.Sessions) != 1 || Config.changed {
+		t.Errorf("pruneEphemeralSessions changed the config: %+v", Config.Sessions)
+	}
+}
+
+func TestPlanRenumbering(t *testing.T) {
+	projects := []temporaryProject{
+		{Number: 0, Path: "/tmp/t0", Session: "t0"},
+		{Number: 2, Path: "/tmp/t2"},
+		{Number: 5, Path: "/tmp/t5"},
+	}
+	moves, blocked := planRenumbering(projects, "/tmp")
+	want := [][2]string{{"/tmp/t2", "/tmp/t1"}, {"/tmp/t5", "/tmp/t2"}}
+	if len(blocked) != 0 || !reflect.DeepEqual(moves, want) {
+		t.Errorf("planRenumbering() = %q, %+v, want %q, no blocked", moves, blocked, want)
+	}
+}
+
+func TestPlanRenumberingBlockedByLiveSession(t *testing.T) {
+	projects := []temporaryProject{
+		{Number: 1, Path: "/tmp/t1"},
+		{Number: 3, Path: "/tmp/t3", Session: "exp"},
+	}
+	moves, blocked := planRenumbering(projects, "/tmp")
+	if len(moves) != 0 {
+		t.Errorf("planRenumbering() moves = %q, want none when a live project must move", moves)
+	}
+	if len(blocked) != 1 || blocked[0].Session != "exp" {
+		t.Errorf("planRenumbering() blocked = %+v, want the exp project", blocked)
+	}
+}
+
+func TestPlanRenumberingContiguous(t *testing.T) {
+	projects := []temporaryProject{{Number: 0, Path: "/tmp/t0"}, {Number: 1, Path: "/tmp/t1", Session: "t1"}}
+	if moves, blocked := planRenumbering(projects, "/tmp"); len(moves) != 0 || len(blocked) != 0 {
+		t.Errorf("planRenumbering() = %q, %+v, want nothing to do", moves, blocked)
+	}
+}
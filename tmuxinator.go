@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Соответствие полей FavouriteSession и проекта tmuxinator:
+//
+//   name           -> name
+//   path           -> root
+//   env            -> pre_window (export KEY=VALUE; выполняется в каждом окне)
+//   layout[].window -> имя окна в windows
+//   layout[].cmd   -> команда окна
+//   layout[].dir   -> root окна
+//   cmd            -> команда первого окна, если её нет в layout (или единственного окна без layout)
+
+// tmuxinatorProject это проект tmuxinator (только поля, которые понимает pr)
+type tmuxinatorProject struct {
+	Name      string                   `yaml:"name"`
+	Root      string                   `yaml:"root"`
+	PreWindow string                   `yaml:"pre_window,omitempty"`
+	Windows   []map[string]interface{} `yaml:"windows"`
+}
+
+// tmuxinatorWindow это окно tmuxinator с собственным каталогом
+type tmuxinatorWindow struct {
+	Root  string   `yaml:"root"`
+	Panes []string `yaml:"panes"`
+}
+
+// exportTmuxinator печатает проект tmuxinator, соответствующий сессии из конфига
+func exportTmuxinator(name string) {
+	fs := Config.Find(name)
+	if fs == nil {
+		fatalf("session %s not found in config", name)
+	}
+
+	project := tmuxinatorProject{
+		Name:    fs.Name,
+		Root:    fs.Path,
+		Windows: []map[string]interface{}{},
+	}
+
+	keys := make([]string, 0, len(fs.Env))
+	for k := range fs.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	exports := []string{}
+	for _, k := range keys {
+		exports = append(exports, fmt.Sprintf("export %s=%s", k, shellQuote(fs.Env[k])))
+	}
+	project.PreWindow = strings.Join(exports, "; ")
+
+	layout := fs.Layout
+	if len(layout) == 0 {
+		layout = []LayoutWindow{{Window: fs.Name}}
+	}
+	for i, lw := range layout {
+		windowName := lw.Window
+		if windowName == "" {
+			windowName = fmt.Sprintf("window%d", i+1)
+		}
+		cmd := lw.Cmd
+		if i == 0 && cmd == "" {
+			cmd = fs.Cmd
+		}
+		var window interface{}
+		if lw.Dir != "" && i > 0 {
+			panes := []string{}
+			if cmd != "" {
+				panes = append(panes, cmd)
+			}
+			window = tmuxinatorWindow{Root: lw.windowDir(fs.Path), Panes: panes}
+		} else if cmd != "" {
+			window = cmd
+		}
+		project.Windows = append(project.Windows, map[string]interface{}{windowName: window})
+	}
+
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	dieIfError(enc.Encode(project))
+	dieIfError(enc.Close())
+}
+
+// importTmuxinator читает проект tmuxinator и добавляет (или обновляет) соответствующую сессию в конфиге
+func importTmuxinator(filename string) {
+	bs, err := os.ReadFile(filename)
+	if err != nil {
+		fatalf("cannot read %s: %s", filename, err)
+	}
+	fs, err := parseTmuxinator(bs)
+	if err != nil {
+		fatalf("%s: %s", filename, err)
+	}
+
+	if existing := Config.Find(fs.Name); existing != nil {
+		existing.Path = fs.Path
+		existing.Layout = fs.Layout
+		for k, v := range fs.Env {
+			if existing.Env == nil {
+				existing.Env = make(map[string]string)
+			}
+			existing.Env[k] = v
+		}
+	} else {
+		Config.Sessions = append(Config.Sessions, fs)
+	}
+	Config.changed = true
+	info("imported %s (%s, %d windows)", fs.Name, fs.Path, len(fs.Layout))
+}
+
+// parseTmuxinator разбирает проект tmuxinator в сессию для конфига.
+// Неподдерживаемые директивы пропускаются с предупреждением.
+func parseTmuxinator(bs []byte) (FavouriteSession, error) {
+	var project map[string]interface{}
+	if err := yaml.Unmarshal(bs, &project); err != nil {
+		return FavouriteSession{}, fmt.Errorf("cannot parse: %s", err)
+	}
+
+	fs := FavouriteSession{
+		Aliases: []string{},
+		Env:     make(map[string]string),
+	}
+	var windows []interface{}
+	for key, value := range project {
+		switch key {
+		case "name", "project_name":
+			fs.Name = fmt.Sprint(value)
+		case "root", "project_root":
+			fs.Path = expandHome(fmt.Sprint(value))
+		case "pre_window":
+			for _, stmt := range splitStatements(fmt.Sprint(value)) {
+				args, err := splitArgs(stmt)
+				if err == nil && len(args) == 2 && args[0] == "export" {
+					if k, v, ok := strings.Cut(args[1], "="); ok {
+						fs.Env[k] = v
+						continue
+					}
+				}
+				warn("skipping unsupported pre_window statement %q", stmt)
+			}
+		case "windows", "tabs":
+			windows, _ = value.([]interface{})
+		default:
+			warn("skipping unsupported directive %q", key)
+		}
+	}
+	if fs.Name == "" || fs.Path == "" {
+		return FavouriteSession{}, fmt.Errorf("project name and root are required")
+	}
+
+	for _, w := range windows {
+		wm, ok := w.(map[string]interface{})
+		if !ok || len(wm) != 1 {
+			warn("skipping unsupported window %v", w)
+			continue
+		}
+		for name, value := range wm {
+			lw := LayoutWindow{Window: name}
+			switch v := value.(type) {
+			case nil:
+			case string:
+				lw.Cmd = v
+			case []interface{}:
+				lw.Cmd = joinCommands(v)
+			case map[string]interface{}:
+				for wk, wv := range v {
+					switch wk {
+					case "root":
+						lw.Dir = expandHome(fmt.Sprint(wv))
+					case "panes":
+						panes, _ := wv.([]interface{})
+						if len(panes) > 1 {
+							warn("window %s: only the first pane is imported", name)
+						}
+						if len(panes) > 0 {
+							if cmd, ok := panes[0].(string); ok {
+								lw.Cmd = cmd
+							} else {
+								warn("window %s: skipping unsupported pane %v", name, panes[0])
+							}
+						}
+					default:
+						warn("window %s: skipping unsupported directive %q", name, wk)
+					}
+				}
+			default:
+				warn("skipping unsupported window %s", name)
+			}
+			fs.Layout = append(fs.Layout, lw)
+		}
+	}
+	return fs, nil
+}
+
+// shellQuote заключает строку в одинарные кавычки для shell. Одинарная кавычка внутри
+// записывается как '"'"', что понимает и splitArgs при импорте.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+// splitStatements разбивает строку shell на команды по ";" вне кавычек
+func splitStatements(line string) []string {
+	stmts := []string{}
+	var cur strings.Builder
+	var quote rune
+	flush := func() {
+		if stmt := strings.TrimSpace(cur.String()); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+		cur.Reset()
+	}
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == ';':
+			flush()
+			continue
+		}
+		cur.WriteRune(r)
+	}
+	flush()
+	return stmts
+}
+
+// joinCommands склеивает список команд окна tmuxinator в одну команду shell
+func joinCommands(cmds []interface{}) string {
+	parts := []string{}
+	for _, c := range cmds {
+		parts = append(parts, fmt.Sprint(c))
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	cases := map[string]string{
+		"":          `''`,
+		"plain":     `'plain'`,
+		"a b":       `'a b'`,
+		`$HOME "x"`: `'$HOME "x"'`,
+		"it's":      `'it'"'"'s'`,
+		"a;b":       `'a;b'`,
+	}
+	for in, want := range cases {
+		if got := shellQuote(in); got != want {
+			t.Errorf("shellQuote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestShellQuoteRoundTrip(t *testing.T) {
+	values := []string{"", "plain", "a b", `$HOME "x"`, "it's", "a;b", `back\slash`}
+	for _, v := range values {
+		stmts := splitStatements("export A=" + shellQuote(v) + "; export B=1")
+		if len(stmts) != 2 {
+			t.Errorf("%q: splitStatements() = %q, want 2 statements", v, stmts)
+			continue
+		}
+		args, err := splitArgs(stmts[0])
+		if err != nil {
+			t.Errorf("%q: splitArgs(%s): %s", v, stmts[0], err)
+			continue
+		}
+		if want := []string{"export", "A=" + v}; !reflect.DeepEqual(args, want) {
+			t.Errorf("%q: splitArgs(%s) = %q, want %q", v, stmts[0], args, want)
+		}
+	}
+}
+
+const sampleTmuxinator = `
+name: shop
+root: ~/work/shop
+pre_window: export PORT=8080; export GREETING='it'"'"'s; fine'
+on_project_start: echo unsupported
+windows:
+  - editor: vim
+  - server:
+      root: ~/work/shop/api
+      panes:
+        - make run
+  - logs:
+      - cd log
+      - tail -f app.log
+  - shell:
+`
+
+func TestParseTmuxinator(t *testing.T) {
+	withHome(t, "/home/user")
+	fs, err := parseTmuxinator([]byte(sampleTmuxinator))
+	if err != nil {
+		t.Fatalf("parseTmuxinator() error: %s", err)
+	}
+	want := FavouriteSession{
+		Name:    "shop",
+		Path:    "/home/user/work/shop",
+		Aliases: []string{},
+		Env:     map[string]string{"PORT": "8080", "GREETING": "it's; fine"},
+		Layout: []LayoutWindow{
+			{Window: "editor", Cmd: "vim"},
+			{Window: "server", Dir: "/home/user/work/shop/api", Cmd: "make run"},
+			{Window: "logs", Cmd: "cd log; tail -f app.log"},
+			{Window: "shell"},
+		},
+	}
+	if !reflect.DeepEqual(fs, want) {
+		t.Errorf("parseTmuxinator() = %+v, want %+v", fs, want)
+	}
+}
+
+func TestParseTmuxinatorRequiresNameAndRoot(t *testing.T) {
+	for _, doc := range []string{"name: shop\n", "root: /src/shop\n", "windows: []\n"} {
+		if fs, err := parseTmuxinator([]byte(doc)); err == nil {
+			t.Errorf("parseTmuxinator(%q) = %+v, want error", doc, fs)
+		}
+	}
+	if _, err := parseTmuxinator([]byte("name: [")); err == nil {
+		t.Error("parseTmuxinator(invalid yaml) succeeded, want error")
+	}
+}
+
+func TestImportTmuxinatorUpdatesExistingSession(t *testing.T) {
+	withHome(t, "/home/user")
+	withConfig(t, FavouritesConfig{Sessions: []FavouriteSession{
+		{Name: "shop", Path: "/old/shop", Cmd: "make", Aliases: []string{"s"}, Env: map[string]string{"PORT": "1", "KEEP": "yes"}},
+	}})
+	fname := filepath.Join(t.TempDir(), "shop.yml")
+	if err := os.WriteFile(fname, []byte(sampleTmuxinator), 0644); err != nil {
+		t.Fatal(err)
+	}
+	importTmuxinator(fname)
+
+	if len(Config.Sessions) != 1 {
+		t.Fatalf("config has %d sessions, want 1", len(Config.Sessions))
+	}
+	fs := Config.Sessions[0]
+	if fs.Path != "/home/user/work/shop" || len(fs.Layout) != 4 {
+		t.Errorf("imported session = %+v, want updated path and 4 windows", fs)
+	}
+	if fs.Cmd != "make" || !reflect.DeepEqual(fs.Aliases, []string{"s"}) {
+		t.Errorf("import changed cmd or aliases: %+v", fs)
+	}
+	wantEnv := map[string]string{"PORT": "8080", "KEEP": "yes", "GREETING": "it's; fine"}
+	if !reflect.DeepEqual(fs.Env, wantEnv) {
+		t.Errorf("env = %v, want %v", fs.Env, wantEnv)
+	}
+	if !Config.changed {
+		t.Error("import did not mark config as changed")
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// printAllTodos печатает непустые .todo всех живых и сохранённых сессий,
+// каждый под заголовком с именем сессии. Каталог, общий для нескольких сессий, печатается один раз.
+func printAllTodos(sessions []TmuxSession) {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	seen := make(map[string]bool)
+	first := true
+	for _, s := range withSavedSessions(sessions) {
+		if seen[s.Path] {
+			continue
+		}
+		seen[s.Path] = true
+		todo := strings.TrimRight(getTodoContents(s.Path), "\n")
+		if strings.TrimSpace(todo) == "" {
+			continue
+		}
+		if !first {
+			fmt.Println()
+		}
+		first = false
+		fmt.Println(headerFmt("%s", s.Name) + " " + s.Path)
+		fmt.Println(todo)
+	}
+}
+
+// appendTodo дописывает строку text в .todo текущего проекта (создавая файл, если его нет)
+// и печатает получившийся TODO целиком
+func appendTodo(text string) {
+	dir := getSessionPath()
+	fname := getTodoFilename(dir)
+	contents := getTodoContents(dir)
+	if contents != "" && !strings.HasSuffix(contents, "\n") {
+		contents += "\n"
+	}
+	contents += text + "\n"
+	err := os.WriteFile(fname, []byte(contents), 0644)
+	dieIfError(err)
+	fmt.Print(contents)
+}
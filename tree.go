@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// dirNode это каталог в дереве сессий
+type dirNode struct {
+	children map[string]*dirNode
+	sessions []TmuxSession // сессии, открытые ровно в этом каталоге
+}
+
+func newDirNode() *dirNode {
+	return &dirNode{children: make(map[string]*dirNode)}
+}
+
+// add добавляет сессию в дерево по компонентам её пути
+func (n *dirNode) add(components []string, s TmuxSession) {
+	if len(components) == 0 {
+		n.sessions = append(n.sessions, s)
+		return
+	}
+	child, ok := n.children[components[0]]
+	if !ok {
+		child = newDirNode()
+		n.children[components[0]] = child
+	}
+	child.add(components[1:], s)
+}
+
+// print печатает поддерево с отступом; цепочки каталогов без сессий и ветвлений склеиваются в одну строку
+func (n *dirNode) print(name string, indent int) {
+	for len(n.sessions) == 0 && len(n.children) == 1 {
+		for childName, child := range n.children {
+			if name == "" || strings.HasSuffix(name, "/") {
+				name += childName
+			} else {
+				name += "/" + childName
+			}
+			n = child
+		}
+	}
+	line := strings.Repeat("  ", indent) + name
+	descriptions := []string{}
+	for _, s := range n.sessions {
+		descriptions = append(descriptions, describeSession(s))
+	}
+	if len(descriptions) > 0 {
+		line += "  [" + strings.Join(descriptions, "; ") + "]"
+	}
+	fmt.Println(line)
+
+	names := make([]string, 0, len(n.children))
+	for childName := range n.children {
+		names = append(names, childName)
+	}
+	sort.Strings(names)
+	for _, childName := range names {
+		n.children[childName].print(childName, indent+1)
+	}
+}
+
+// describeSession возвращает краткое описание сессии для дерева
+func describeSession(s TmuxSession) string {
+	if s.WindowsCount == 0 {
+		return s.Name
+	}
+	return fmt.Sprintf("%s: %d windows, %s%s", s.Name, s.WindowsCount, s.FmtLastActivity(), s.FmtAttached())
+}
+
+// printSessionTree печатает сессии деревом каталогов (с флагом -a вместе с сохранёнными)
+func printSessionTree(sessions []TmuxSession) {
+	allSessions := sessions
+	if *fShowAllSessions {
+		allSessions = withSavedSessions(sessions)
+	}
+	root := newDirNode()
+	for _, s := range allSessions {
+		p := s.Path
+		var components []string
+		if p == Home || strings.HasPrefix(p, Home+"/") {
+			components = append([]string{"~"}, strings.Split(strings.Trim(strings.TrimPrefix(p, Home), "/"), "/")...)
+		} else {
+			components = append([]string{"/"}, strings.Split(strings.Trim(p, "/"), "/")...)
+		}
+		if components[len(components)-1] == "" {
+			components = components[:len(components)-1]
+		}
+		root.add(components, s)
+	}
+	names := make([]string, 0, len(root.children))
+	for name := range root.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		root.children[name].print(name, 0)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// killWindow закрывает окно target (в формате session:window; пустая строка означает текущее окно).
+// Если окно последнее в сессии, вместе с ним закроется и сессия, поэтому спрашиваем подтверждение
+// и предлагаем сначала переключиться на предыдущую сессию.
+func killWindow(sessions []TmuxSession, target string) {
+	if target == "" && os.Getenv("TMUX") == "" {
+		fatalf("not inside tmux: specify window as session:window")
+	}
+	args := []string{"display-message", "-p"}
+	if target != "" {
+		args = append(args, "-t", target)
+	}
+	args = append(args, "#{session_name}\t#{session_windows}\t#{window_index}")
+	out, err := exec.Command("tmux", args...).CombinedOutput()
+	if err != nil {
+		fatalf("cannot find window %s: %s", target, strings.TrimSpace(string(out)))
+	}
+	parts := strings.Split(strings.TrimSpace(string(out)), "\t")
+	if len(parts) != 3 {
+		fatalf("unexpected tmux output: %s", out)
+	}
+	sessionName := parts[0]
+	windowsCount, _ := strconv.Atoi(parts[1])
+	windowTarget := sessionName + ":" + parts[2]
+
+	if windowsCount == 1 {
+		fmt.Printf("window %s is the last window of session %s: the session will be killed too\n", windowTarget, sessionName)
+		if !confirm("kill it anyway?") {
+			return
+		}
+		if os.Getenv("TMUX") != "" && sessionName == getCurrentSessionName() {
+			for _, s := range sortByActivity(sessions) {
+				if s.Name == sessionName {
+					continue
+				}
+				if confirm(fmt.Sprintf("switch to session %s first?", s.Name)) {
+					switchToSession(s.Name)
+				}
+				break
+			}
+		}
+	}
+
+	out, err = exec.Command("tmux", "kill-window", "-t", windowTarget).CombinedOutput()
+	if err != nil {
+		fatalf("cannot kill window %s: %s", windowTarget, strings.TrimSpace(string(out)))
+	}
+}
+
+// windowNames возвращает имена окон живой сессии. Для сессий, которых нет в tmux,
+// и при ошибке tmux возвращает пустой список: список сессий из-за этого не должен ломаться.
+func windowNames(s TmuxSession) []string {
+	if s.WindowsCount == 0 {
+		return nil
+	}
+	// = требует точного совпадения имени, иначе tmux может выбрать сессию по префиксу
+	out, err := exec.Command("tmux", "list-windows", "-t", "="+s.Name, "-F", "#{window_name}").Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+}
+
+// openAsWindow открывает новое окно в текущей сессии в каталоге сессии name
+// (вместо переключения на неё). Окно называется так же, как сессия.
+func openAsWindow(name string) {
+	out, err := exec.Command("tmux", "display-message", "-p", "-t", "="+name+":", "#{session_path}").CombinedOutput()
+	if err != nil {
+		fatalf("cannot find session %s: %s", name, strings.TrimSpace(string(out)))
+	}
+	path := strings.TrimSpace(string(out))
+	out, err = exec.Command("tmux", "new-window", "-c", path, "-n", name).CombinedOutput()
+	if err != nil {
+		fatalf("cannot open window for %s: %s: %s", name, err, strings.TrimSpace(string(out)))
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// windowNameContext содержит поля, доступные в шаблоне on_switch_window_name
+type windowNameContext struct {
+	Name   string // имя сессии
+	Path   string // каталог сессии
+	Base   string // последний элемент пути каталога
+	Branch string // текущая ветка git (пусто, если каталог не в репозитории)
+}
+
+// renameWindowOnSwitch переименовывает активное окно сессии name по шаблону
+// on_switch_window_name из конфига (например, "{{.Base}}:{{.Branch}}").
+// Ошибки не фатальны: переключение на сессию важнее имени окна.
+func renameWindowOnSwitch(name string) {
+	fs := Config.Find(name)
+	if fs == nil || fs.OnSwitchWindowName == "" {
+		return
+	}
+	tmpl, err := template.New("window").Parse(fs.OnSwitchWindowName)
+	if err != nil {
+		warn("invalid on_switch_window_name for %s: %s", name, err)
+		return
+	}
+	path := fs.ExpandedPath()
+	ctx := windowNameContext{
+		Name: name,
+		Path: path,
+		Base: filepath.Base(path),
+	}
+	ctx.Branch, _ = gitBranch(path)
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, ctx); err != nil {
+		warn("cannot render on_switch_window_name for %s: %s", name, err)
+		return
+	}
+	windowName := strings.TrimSpace(b.String())
+	if windowName == "" {
+		return
+	}
+	out, err := exec.Command("tmux", "rename-window", "-t", name+":", windowName).CombinedOutput()
+	if err != nil {
+		warn("cannot rename window of %s: %s: %s", name, err, strings.TrimSpace(string(out)))
+	}
+}